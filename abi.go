@@ -22,14 +22,39 @@ const (
 	abiFileSuffix = ".abi"
 )
 
-// LoadABI load ABI from file or string
+// LoadABI load ABI from file or string. Besides a raw ABI, the JSON may be a Hardhat or Foundry artifact or a solc
+// --combined-json output, and the path may be a directory of artifacts; the ABIs of multiple contracts are merged.
+// Human-readable signatures (see ParseHumanReadableABI) are accepted one per line or as a JSON string array
 func LoadABI(strABI string) (contractABI abi.ABI, err error) {
-	if strings.HasSuffix(strABI, abiFileSuffix) {
+	s := strings.TrimSpace(strABI)
+	switch {
+	case strings.HasPrefix(s, "["):
+		if signatures, ok := humanReadableSignatures(s); ok {
+			return ParseHumanReadableABI(signatures...)
+		}
+		return loadABIFromString(strABI)
+	case isHumanReadableABI(s):
+		return ParseHumanReadableABI(strings.Split(s, "\n")...)
+	case strings.HasSuffix(s, abiFileSuffix):
 		return loadABIFromFile(strABI)
+	case strings.HasPrefix(s, "{"):
+		return loadABIFromArtifacts(strABI)
+	}
+	if _, err = os.Stat(strABI); err == nil {
+		return loadABIFromArtifacts(strABI)
 	}
 	return loadABIFromString(strABI)
 }
 
+// mustLoadABI load ABI from file or string and panic on error, used for the built-in ABI definitions
+func mustLoadABI(strABI string) abi.ABI {
+	contractABI, err := LoadABI(strABI)
+	if err != nil {
+		panic(err.Error())
+	}
+	return contractABI
+}
+
 func loadABIFromFile(strAbiFile string) (contractABI abi.ABI, err error) {
 	var file *os.File
 	file, err = os.Open(strAbiFile)
@@ -53,6 +78,135 @@ func loadABIFromString(strAbi string) (contractABI abi.ABI, err error) {
 	return contractABI, nil
 }
 
+func loadABIFromArtifacts(strArtifacts string) (contractABI abi.ABI, err error) {
+	var artifacts []*ContractArtifact
+	if artifacts, err = LoadArtifacts(strArtifacts); err != nil {
+		return abi.ABI{}, err
+	}
+	abis := make([]abi.ABI, len(artifacts))
+	for i, artifact := range artifacts {
+		abis[i] = artifact.ABI
+	}
+	return MergeABI(abis...), nil
+}
+
+// MergeABI merge the methods, events and errors of ABIs into one. An entry of the same name but a different
+// signature (or different indexed arguments for events, e.g. Transfer of ERC20 and ERC721) is renamed with a
+// numeric suffix as overloaded entries (e.g. transfer0), use EventByLog to look up events by topics
+func MergeABI(abis ...abi.ABI) abi.ABI {
+	merged := abi.ABI{
+		Methods: make(map[string]abi.Method),
+		Events:  make(map[string]abi.Event),
+		Errors:  make(map[string]abi.Error),
+	}
+	for _, contractABI := range abis {
+		if len(merged.Constructor.Inputs) == 0 {
+			merged.Constructor = contractABI.Constructor
+		}
+		if !merged.HasFallback() {
+			merged.Fallback = contractABI.Fallback
+		}
+		if !merged.HasReceive() {
+			merged.Receive = contractABI.Receive
+		}
+		for _, name := range sortedKeys(contractABI.Methods) {
+			method := contractABI.Methods[name]
+			if name = mergeName(name, method.Sig, func(name string) (string, bool) {
+				m, ok := merged.Methods[name]
+				return m.Sig, ok
+			}); name != "" {
+				method.Name = name
+				merged.Methods[name] = method
+			}
+		}
+		for _, name := range sortedKeys(contractABI.Events) {
+			event := contractABI.Events[name]
+			if name = mergeName(name, eventKey(event), func(name string) (string, bool) {
+				e, ok := merged.Events[name]
+				return eventKey(e), ok
+			}); name != "" {
+				event.Name = name
+				merged.Events[name] = event
+			}
+		}
+		for _, name := range sortedKeys(contractABI.Errors) {
+			e := contractABI.Errors[name]
+			if name = mergeName(name, e.Sig, func(name string) (string, bool) {
+				e, ok := merged.Errors[name]
+				return e.Sig, ok
+			}); name != "" {
+				e.Name = name
+				merged.Errors[name] = e
+			}
+		}
+	}
+	return merged
+}
+
+// mergeName returns the free name for an entry of key, or empty if an entry of the same key exists
+func mergeName(name, key string, lookup func(name string) (string, bool)) string {
+	for i := -1; ; i++ {
+		candidate := name
+		if i >= 0 {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		existing, ok := lookup(candidate)
+		if !ok {
+			return candidate
+		}
+		if existing == key {
+			return ""
+		}
+	}
+}
+
+// eventKey returns the signature of event with the indexed arguments marked, events of the same signature
+// but different indexed arguments share the topic but not the log layout
+func eventKey(event abi.Event) string {
+	var sb strings.Builder
+	sb.WriteString(event.Sig)
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			sb.WriteString(" indexed")
+		} else {
+			sb.WriteString(" -")
+		}
+	}
+	if event.Anonymous {
+		sb.WriteString(" anonymous")
+	}
+	return sb.String()
+}
+
+// EventByLog lookup the event of log topics, the number of indexed arguments must match the topics so that
+// events sharing the topic (e.g. Transfer of ERC20 and ERC721 in a merged ABI) are told apart
+func EventByLog(contractABI abi.ABI, topics []common.Hash) (*abi.Event, error) {
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("anonymous log without topics")
+	}
+	found := false
+	for _, name := range sortedKeys(contractABI.Events) {
+		event := contractABI.Events[name]
+		if event.ID != topics[0] {
+			continue
+		}
+		found = true
+		indexed := 0
+		for _, arg := range event.Inputs {
+			if arg.Indexed {
+				indexed++
+			}
+		}
+		if indexed == len(topics)-1 {
+			return &event, nil
+		}
+	}
+	if found {
+		return nil, fmt.Errorf("event id %s has no definition of %d indexed arguments", topics[0].Hex(), len(topics)-1)
+	}
+	return nil, fmt.Errorf("event id %s not found", topics[0].Hex())
+}
+
 // MakeTopic converts a filter query argument into a filter topic.
 // NOTE: This was copied from accounts/abi/topics.go
 func MakeTopic(rule interface{}) (common.Hash, error) {
@@ -166,3 +320,45 @@ func PackNum(value reflect.Value) []byte {
 		panic("abi: fatal error")
 	}
 }
+
+var (
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector  = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons are the solidity panic codes, see https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "pop() on an empty array",
+	0x32: "out-of-bounds or negative index access of an array, bytesN or array slice",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// UnpackRevert decode revert data of Error(string) or Panic(uint256)
+func UnpackRevert(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("revert data %x too short", data)
+	}
+	switch {
+	case bytes.Equal(data[:4], revertSelector):
+		return abi.UnpackRevert(data)
+	case bytes.Equal(data[:4], panicSelector):
+		typ, _ := abi.NewType("uint256", "", nil)
+		values, err := (abi.Arguments{{Type: typ}}).UnpackValues(data[4:])
+		if err != nil {
+			return "", err
+		}
+		code := values[0].(*big.Int)
+		if reason, ok := panicReasons[code.Uint64()]; code.IsUint64() && ok {
+			return fmt.Sprintf("panic: %s (0x%x)", reason, code), nil
+		}
+		return fmt.Sprintf("panic: unknown code 0x%x", code), nil
+	}
+	return "", fmt.Errorf("revert data selector %x unknown", data[:4])
+}
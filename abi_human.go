@@ -0,0 +1,387 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// humanReadableKinds is the leading keyword of human-readable ABI signatures
+var humanReadableKinds = map[string]bool{
+	"function":    true,
+	"event":       true,
+	"error":       true,
+	"constructor": true,
+	"fallback":    true,
+	"receive":     true,
+}
+
+// humanEntry is the JSON ABI entry of a human-readable signature
+type humanEntry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name,omitempty"`
+	Inputs          []humanArg `json:"inputs"`
+	Outputs         []humanArg `json:"outputs,omitempty"`
+	StateMutability string     `json:"stateMutability,omitempty"`
+	Anonymous       bool       `json:"anonymous,omitempty"`
+}
+
+// humanArg is the JSON ABI argument of a human-readable parameter
+type humanArg struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Indexed    bool       `json:"indexed,omitempty"`
+	Components []humanArg `json:"components,omitempty"`
+}
+
+// ParseHumanReadableABI parse human-readable signatures into ABI, such as
+//
+//	function transfer(address to, uint256 amount) returns (bool)
+//	function swap((address tokenIn, uint256 amount)[] paths) payable
+//	event Transfer(address indexed from, address indexed to, uint256 value)
+//	error InsufficientBalance(uint256 available, uint256 required)
+//	constructor(string name, string symbol)
+//
+// go-ethereum does not support unnamed tuple components, they are named field0, field1...
+func ParseHumanReadableABI(signatures ...string) (contractABI abi.ABI, err error) {
+	entries := make([]*humanEntry, 0, len(signatures))
+	for _, sig := range signatures {
+		sig = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sig), ";"))
+		if sig == "" || strings.HasPrefix(sig, "//") {
+			continue
+		}
+		var entry *humanEntry
+		if entry, err = parseHumanSignature(sig); err != nil {
+			return abi.ABI{}, fmt.Errorf("parse signature [%s] error [%s]", sig, err)
+		}
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	return loadABIFromString(string(data))
+}
+
+// isHumanReadableABI reports whether the first line out of blank and comment lines starts with a human-readable
+// signature keyword
+func isHumanReadableABI(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		words := strings.FieldsFunc(line, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+		return len(words) != 0 && humanReadableKinds[words[0]]
+	}
+	return false
+}
+
+// humanReadableSignatures returns the signatures of a JSON string array such as ethers.js human-readable ABI
+func humanReadableSignatures(s string) ([]string, bool) {
+	var signatures []string
+	if err := json.Unmarshal([]byte(s), &signatures); err != nil || len(signatures) == 0 {
+		return nil, false
+	}
+	found := false
+	for _, sig := range signatures {
+		if sig = strings.TrimSpace(sig); sig == "" || strings.HasPrefix(sig, "//") {
+			continue
+		}
+		if !isHumanReadableABI(sig) {
+			return nil, false
+		}
+		found = true
+	}
+	return signatures, found
+}
+
+func parseHumanSignature(sig string) (*humanEntry, error) {
+	open := strings.IndexByte(sig, '(')
+	if open < 0 {
+		return nil, fmt.Errorf("missing '('")
+	}
+	kind, name := cutWord(sig[:open])
+	name = strings.TrimSpace(name)
+	if !humanReadableKinds[kind] {
+		return nil, fmt.Errorf("unknown keyword [%s]", kind)
+	}
+	params, rest, err := cutParenthesized(sig[open:])
+	if err != nil {
+		return nil, err
+	}
+	entry := &humanEntry{Type: kind, Name: name}
+	if entry.Inputs, err = parseHumanParams(params, kind == "event"); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "function", "event", "error":
+		if !isIdentifier(name) {
+			return nil, fmt.Errorf("invalid %s name [%s]", kind, name)
+		}
+	default:
+		if name != "" {
+			return nil, fmt.Errorf("%s has no name", kind)
+		}
+	}
+	if kind == "function" || kind == "constructor" || kind == "fallback" || kind == "receive" {
+		entry.StateMutability = "nonpayable"
+	}
+	if kind == "receive" {
+		entry.StateMutability = "payable"
+	}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		var word string
+		word, rest = cutWord(rest)
+		switch word {
+		case "view", "pure", "payable", "nonpayable":
+			entry.StateMutability = word
+		case "constant":
+			entry.StateMutability = "view"
+		case "anonymous":
+			if kind != "event" {
+				return nil, fmt.Errorf("only event can be anonymous")
+			}
+			entry.Anonymous = true
+		case "returns":
+			if kind != "function" {
+				return nil, fmt.Errorf("only function has returns")
+			}
+			if params, rest, err = cutParenthesized(strings.TrimSpace(rest)); err != nil {
+				return nil, err
+			}
+			if entry.Outputs, err = parseHumanParams(params, false); err != nil {
+				return nil, err
+			}
+			if entry.Outputs == nil {
+				entry.Outputs = []humanArg{}
+			}
+		case "external", "public", "internal", "private", "virtual", "override":
+			// visibility and inheritance modifiers are ignored
+		default:
+			return nil, fmt.Errorf("unexpected [%s]", word)
+		}
+	}
+	return entry, nil
+}
+
+// parseHumanParams parse comma separated parameters: type [indexed] [memory|calldata|storage] [name]
+func parseHumanParams(params string, event bool) (args []humanArg, err error) {
+	if strings.TrimSpace(params) == "" {
+		return nil, nil
+	}
+	for _, param := range splitTopLevel(params) {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			return nil, fmt.Errorf("empty parameter")
+		}
+		var arg humanArg
+		var rest string
+		if strings.HasPrefix(param, "(") || strings.HasPrefix(param, "tuple(") {
+			var components string
+			if components, rest, err = cutParenthesized(strings.TrimPrefix(param, "tuple")); err != nil {
+				return nil, err
+			}
+			if arg.Components, err = parseHumanParams(components, false); err != nil {
+				return nil, err
+			}
+			for i := range arg.Components {
+				if arg.Components[i].Name == "" {
+					arg.Components[i].Name = fmt.Sprintf("field%d", i)
+				}
+			}
+			suffix := rest
+			if i := strings.IndexFunc(rest, func(r rune) bool { return r != '[' && r != ']' && (r < '0' || r > '9') }); i >= 0 {
+				suffix = rest[:i]
+			}
+			arg.Type, rest = "tuple"+suffix, rest[len(suffix):]
+		} else {
+			arg.Type, rest = cutWord(param)
+			arg.Type = normalizeHumanType(arg.Type)
+		}
+		for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+			var word string
+			word, rest = cutWord(rest)
+			switch {
+			case word == "indexed" && event:
+				arg.Indexed = true
+			case word == "memory" || word == "calldata" || word == "storage" || (word == "payable" && arg.Type == "address"):
+			case arg.Name == "" && isIdentifier(word):
+				arg.Name = word
+			default:
+				return nil, fmt.Errorf("unexpected [%s] in parameter [%s]", word, param)
+			}
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// normalizeHumanType expand the type aliases uint, int and byte
+func normalizeHumanType(typ string) string {
+	base, suffix := typ, ""
+	if i := strings.IndexByte(typ, '['); i >= 0 {
+		base, suffix = typ[:i], typ[i:]
+	}
+	switch base {
+	case "uint":
+		base = "uint256"
+	case "int":
+		base = "int256"
+	case "byte":
+		base = "bytes1"
+	}
+	return base + suffix
+}
+
+// cutParenthesized returns the content of the leading parentheses and the rest
+func cutParenthesized(s string) (inner, rest string, err error) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", fmt.Errorf("missing '(' at [%s]", s)
+	}
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("missing ')' at [%s]", s)
+}
+
+// splitTopLevel split s by the commas out of parentheses
+func splitTopLevel(s string) (parts []string) {
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// cutWord returns the leading word of s and the rest
+func cutWord(s string) (word, rest string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t\r\n"); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && c != '$' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatHumanReadableABI render the ABI as human-readable signatures: constructor, fallback and receive, then the
+// functions, events and errors sorted by name
+func FormatHumanReadableABI(contractABI abi.ABI) (signatures []string) {
+	if len(contractABI.Constructor.Inputs) != 0 || contractABI.Constructor.IsPayable() {
+		signatures = append(signatures, "constructor("+formatHumanArgs(contractABI.Constructor.Inputs, false)+")"+
+			formatMutability(contractABI.Constructor))
+	}
+	if contractABI.HasFallback() {
+		signatures = append(signatures, "fallback()"+formatMutability(contractABI.Fallback))
+	}
+	if contractABI.HasReceive() {
+		signatures = append(signatures, "receive() payable")
+	}
+	for _, name := range sortedKeys(contractABI.Methods) {
+		method := contractABI.Methods[name]
+		sig := "function " + method.RawName + "(" + formatHumanArgs(method.Inputs, false) + ")" + formatMutability(method)
+		if len(method.Outputs) != 0 {
+			sig += " returns (" + formatHumanArgs(method.Outputs, false) + ")"
+		}
+		signatures = append(signatures, sig)
+	}
+	for _, name := range sortedKeys(contractABI.Events) {
+		event := contractABI.Events[name]
+		sig := "event " + event.RawName + "(" + formatHumanArgs(event.Inputs, true) + ")"
+		if event.Anonymous {
+			sig += " anonymous"
+		}
+		signatures = append(signatures, sig)
+	}
+	for _, name := range sortedKeys(contractABI.Errors) {
+		e := contractABI.Errors[name]
+		signatures = append(signatures, "error "+e.Name+"("+formatHumanArgs(e.Inputs, false)+")")
+	}
+	return signatures
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatMutability returns the state mutability suffix, nonpayable is omitted
+func formatMutability(method abi.Method) string {
+	switch {
+	case method.StateMutability != "" && method.StateMutability != "nonpayable":
+		return " " + method.StateMutability
+	case method.StateMutability == "" && method.IsConstant():
+		return " view"
+	case method.StateMutability == "" && method.IsPayable():
+		return " payable"
+	}
+	return ""
+}
+
+func formatHumanArgs(args abi.Arguments, event bool) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = formatHumanType(arg.Type)
+		if event && arg.Indexed {
+			parts[i] += " indexed"
+		}
+		if arg.Name != "" {
+			parts[i] += " " + arg.Name
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatHumanType render the type with the named components of tuples, e.g. (address token, uint256 amount)[]
+func formatHumanType(typ abi.Type) string {
+	switch typ.T {
+	case abi.TupleTy:
+		parts := make([]string, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			parts[i] = formatHumanType(*elem) + " " + typ.TupleRawNames[i]
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	case abi.SliceTy:
+		return formatHumanType(*typ.Elem) + "[]"
+	case abi.ArrayTy:
+		return fmt.Sprintf("%s[%d]", formatHumanType(*typ.Elem), typ.Size)
+	}
+	return typ.String()
+}
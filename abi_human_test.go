@@ -0,0 +1,168 @@
+package ethclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHumanReadableABI(t *testing.T) {
+	contractABI, err := ParseHumanReadableABI(
+		"// ERC20 and some more",
+		"function transfer(address payable to, uint amount) external returns (bool);",
+		"function balanceOf(address owner) view returns (uint256 balance)",
+		"function swap((address tokenIn, uint256 amount)[2][] paths, bytes calldata data) payable",
+		"function route(tuple(address pool, (uint24 fee, bool zeroForOne) hop) route) pure returns (int, byte, uint8[])",
+		"event\tTransfer(address indexed from, address indexed to, uint256 value)",
+		"event Log(bytes32 indexed topic, string) anonymous",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+		"constructor(string memory name, string memory symbol) payable",
+		"fallback() external",
+		"receive() external payable",
+		"",
+	)
+	if err != nil {
+		t.Fatalf("parse human-readable abi error %s", err)
+	}
+	methods := map[string]struct {
+		sig, mutability string
+		outputs         []string
+	}{
+		"transfer":  {"transfer(address,uint256)", "nonpayable", []string{"bool"}},
+		"balanceOf": {"balanceOf(address)", "view", []string{"uint256"}},
+		"swap":      {"swap((address,uint256)[2][],bytes)", "payable", nil},
+		"route":     {"route((address,(uint24,bool)))", "pure", []string{"int256", "bytes1", "uint8[]"}},
+	}
+	if len(contractABI.Methods) != len(methods) {
+		t.Fatalf("parsed %d methods, want %d", len(contractABI.Methods), len(methods))
+	}
+	for name, want := range methods {
+		method := contractABI.Methods[name]
+		if method.Sig != want.sig || method.StateMutability != want.mutability || len(method.Outputs) != len(want.outputs) {
+			t.Errorf("method %s parsed as %s %s", name, method.Sig, method.StateMutability)
+			continue
+		}
+		for i, typ := range want.outputs {
+			if method.Outputs[i].Type.String() != typ {
+				t.Errorf("method %s output %d type %s, want %s", name, i, method.Outputs[i].Type, typ)
+			}
+		}
+	}
+	if names := contractABI.Methods["swap"].Inputs[0].Type.Elem.Elem.TupleRawNames; !reflect.DeepEqual(names, []string{"tokenIn", "amount"}) {
+		t.Errorf("tuple component names %v", names)
+	}
+	if balance := contractABI.Methods["balanceOf"].Outputs[0].Name; balance != "balance" {
+		t.Errorf("named output %s", balance)
+	}
+
+	transfer := contractABI.Events["Transfer"]
+	if transfer.Sig != "Transfer(address,address,uint256)" || !transfer.Inputs[0].Indexed || !transfer.Inputs[1].Indexed ||
+		transfer.Inputs[2].Indexed || transfer.Anonymous {
+		t.Errorf("event Transfer parsed as %s", transfer.String())
+	}
+	if log := contractABI.Events["Log"]; !log.Anonymous || !log.Inputs[0].Indexed || log.Inputs[1].Type.String() != "string" {
+		t.Errorf("anonymous event Log parsed as %s", log.String())
+	}
+	if e, ok := contractABI.Errors["InsufficientBalance"]; !ok || e.Sig != "InsufficientBalance(uint256,uint256)" {
+		t.Errorf("error InsufficientBalance parsed as %s", e.Sig)
+	}
+	if len(contractABI.Constructor.Inputs) != 2 || !contractABI.Constructor.IsPayable() {
+		t.Errorf("constructor parsed as %s", contractABI.Constructor.String())
+	}
+	if !contractABI.HasFallback() || !contractABI.HasReceive() {
+		t.Errorf("fallback or receive not parsed")
+	}
+}
+
+func TestParseHumanReadableABIError(t *testing.T) {
+	for _, sig := range []string{
+		"function transfer",
+		"function transfer(address to",
+		"function (address to)",
+		"function 1transfer()",
+		"method transfer()",
+		"function transfer(address to,)",
+		"function transfer(address to) returns bool",
+		"function transfer(address to) virtual bogus",
+		"function transfer(address indexed to)",
+		"function transfer(address to from)",
+		"function transfer(foo amount)",
+		"function pay(uint256 payable amount)",
+		"event Transfer(address from) returns (bool)",
+		"error Failed() anonymous",
+		"constructor named()",
+		"function swap((address, uint256 amount)[x] paths)",
+	} {
+		if _, err := ParseHumanReadableABI(sig); err == nil {
+			t.Errorf("parse [%s] should fail", sig)
+		}
+	}
+}
+
+func TestFormatHumanReadableABI(t *testing.T) {
+	signatures := []string{
+		"constructor(string name) payable",
+		"fallback() payable",
+		"receive() payable",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function swap((address tokenIn, uint256 amount)[2][] paths, bytes data) payable returns ((uint256 out, bool ok)[])",
+		"event Log(bytes32 indexed topic, string message) anonymous",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	}
+	contractABI, err := ParseHumanReadableABI(signatures...)
+	if err != nil {
+		t.Fatalf("parse human-readable abi error %s", err)
+	}
+	formatted := FormatHumanReadableABI(contractABI)
+	if !reflect.DeepEqual(formatted, signatures) {
+		t.Fatalf("formatted signatures\n%q\nwant\n%q", formatted, signatures)
+	}
+	// the formatted signatures parse back to the same ABI
+	again, err := ParseHumanReadableABI(formatted...)
+	if err != nil {
+		t.Fatalf("parse formatted signatures error %s", err)
+	}
+	for name, method := range contractABI.Methods {
+		if again.Methods[name].String() != method.String() {
+			t.Errorf("method %s round trip as %s", method, again.Methods[name].String())
+		}
+	}
+	for name, event := range contractABI.Events {
+		if again.Events[name].String() != event.String() || again.Events[name].Anonymous != event.Anonymous {
+			t.Errorf("event %s round trip as %s", event, again.Events[name].String())
+		}
+	}
+	// JSON ABI renders the same
+	if jsonABI := FormatHumanReadableABI(mustLoadABI(erc20TransferABI)); !reflect.DeepEqual(jsonABI, []string{
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+	}) {
+		t.Errorf("formatted json abi %q", jsonABI)
+	}
+}
+
+func TestLoadABIHumanReadable(t *testing.T) {
+	tests := map[string]string{
+		"lines":            "function transfer(address to, uint256 amount) returns (bool)\nevent Transfer(address indexed from, address indexed to, uint256 value)",
+		"comments":         "// ERC20\n\n  // transfer only\r\nfunction transfer(address to, uint256 amount) returns (bool)\r\n// events\r\nevent Transfer(address indexed from, address indexed to, uint256 value)\r\n",
+		"tabs":             "\tfunction\ttransfer(address\tto, uint256 amount)\treturns (bool)\nevent\tTransfer(address indexed from, address indexed to, uint256 value)",
+		"trailing newline": "function transfer(address to, uint256 amount) returns (bool)\nevent Transfer(address indexed from, address indexed to, uint256 value)\n",
+		"json array":       `["function transfer(address to, uint256 amount) returns (bool)", "event Transfer(address indexed from, address indexed to, uint256 value)"]`,
+		"json comment":     `[" // ERC20", "function transfer(address to, uint256 amount) returns (bool)", "event Transfer(address indexed from, address indexed to, uint256 value)"]`,
+	}
+	for name, s := range tests {
+		contractABI, err := LoadABI(s)
+		if err != nil {
+			t.Errorf("%s: load human-readable abi error %s", name, err)
+			continue
+		}
+		if len(contractABI.Methods) != 1 || len(contractABI.Events) != 1 || contractABI.Methods["transfer"].Sig != "transfer(address,uint256)" {
+			t.Errorf("%s: loaded %d methods and %d events", name, len(contractABI.Methods), len(contractABI.Events))
+		}
+	}
+	for _, s := range []string{"", "// only comment", "functions.abi", "function.json", "\n\nevents(x)"} {
+		if isHumanReadableABI(s) {
+			t.Errorf("[%s] detected as human-readable abi", s)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ethclient
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ABIRegistry resolves contract ABIs by address, the ABIs registered without address are
+// searched for any contract when the address is not registered or misses the selector
+type ABIRegistry struct {
+	lock      sync.RWMutex
+	contracts map[common.Address]abi.ABI
+	proxies   map[common.Address]common.Address // proxy to implementation
+	abis      []abi.ABI
+}
+
+func NewABIRegistry(abis ...abi.ABI) *ABIRegistry {
+	return &ABIRegistry{
+		contracts: make(map[common.Address]abi.ABI),
+		proxies:   make(map[common.Address]common.Address),
+		abis:      abis,
+	}
+}
+
+// Register bind ABI to a contract address
+func (r *ABIRegistry) Register(strAddress string, contractABI abi.ABI) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.contracts[Hex2Address(strAddress)] = contractABI
+}
+
+// RegisterABI add an ABI searched for any contract address
+func (r *ABIRegistry) RegisterABI(contractABI abi.ABI) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.abis = append(r.abis, contractABI)
+}
+
+// RegisterProxy bind a proxy to its implementation, the ABI of implementation is used to decode the calls and
+// events of proxy
+func (r *ABIRegistry) RegisterProxy(strProxy, strImplementation string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.proxies[Hex2Address(strProxy)] = Hex2Address(strImplementation)
+}
+
+// ABI returns the ABI bound to contract address, or to its implementation if it is a registered proxy
+func (r *ABIRegistry) ABI(strAddress string) (contractABI abi.ABI, ok bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	addr := Hex2Address(strAddress)
+	if contractABI, ok = r.contracts[addr]; ok {
+		return contractABI, ok
+	}
+	// an unregistered proxy has no implementation, not the zero address
+	impl, ok := r.proxies[addr]
+	if !ok {
+		return abi.ABI{}, false
+	}
+	contractABI, ok = r.contracts[impl]
+	return contractABI, ok
+}
+
+// Implementation returns the implementation of a registered proxy
+func (r *ABIRegistry) Implementation(strProxy string) (impl common.Address, ok bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	impl, ok = r.proxies[Hex2Address(strProxy)]
+	return impl, ok
+}
+
+// candidates returns the ABI bound to address and its implementation (if any) followed by the ABIs without address
+func (r *ABIRegistry) candidates(addr common.Address) []abi.ABI {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	abis := make([]abi.ABI, 0, len(r.abis)+2)
+	if contractABI, ok := r.contracts[addr]; ok {
+		abis = append(abis, contractABI)
+	}
+	if impl, ok := r.proxies[addr]; ok {
+		if contractABI, ok := r.contracts[impl]; ok {
+			abis = append(abis, contractABI)
+		}
+	}
+	return append(abis, r.abis...)
+}
+
+// MethodById lookup method by 4 bytes selector for contract address
+func (r *ABIRegistry) MethodById(strAddress string, id []byte) (*abi.Method, abi.ABI, error) {
+	if len(id) < 4 {
+		return nil, abi.ABI{}, fmt.Errorf("method id %x too short", id)
+	}
+	for _, contractABI := range r.candidates(Hex2Address(strAddress)) {
+		if method, err := contractABI.MethodById(id[:4]); err == nil {
+			return method, contractABI, nil
+		}
+	}
+	if impl, ok := r.Implementation(strAddress); ok {
+		return nil, abi.ABI{}, fmt.Errorf("method id %x of proxy [%s] not found in the ABI of implementation [%s]", id[:4], strAddress, impl)
+	}
+	return nil, abi.ABI{}, fmt.Errorf("method id %x of contract [%s] not found", id[:4], strAddress)
+}
+
+// EventByID lookup event by topic for contract address
+func (r *ABIRegistry) EventByID(strAddress string, topic common.Hash) (*abi.Event, abi.ABI, error) {
+	for _, contractABI := range r.candidates(Hex2Address(strAddress)) {
+		if event, err := contractABI.EventByID(topic); err == nil {
+			return event, contractABI, nil
+		}
+	}
+	return nil, abi.ABI{}, fmt.Errorf("event id %s of contract [%s] not found", topic.Hex(), strAddress)
+}
+
+// EventByLog lookup event by log topics for contract address, see EventByLog
+func (r *ABIRegistry) EventByLog(strAddress string, topics []common.Hash) (*abi.Event, abi.ABI, error) {
+	for _, contractABI := range r.candidates(Hex2Address(strAddress)) {
+		if event, err := EventByLog(contractABI, topics); err == nil {
+			return event, contractABI, nil
+		}
+	}
+	if len(topics) == 0 {
+		return nil, abi.ABI{}, fmt.Errorf("log of contract [%s] has no topics", strAddress)
+	}
+	return nil, abi.ABI{}, fmt.Errorf("event id %s of contract [%s] not found", topics[0].Hex(), strAddress)
+}
+
+// ErrorByID lookup custom error by 4 bytes selector for contract address
+func (r *ABIRegistry) ErrorByID(strAddress string, id []byte) (*abi.Error, abi.ABI, error) {
+	if len(id) < 4 {
+		return nil, abi.ABI{}, fmt.Errorf("error id %x too short", id)
+	}
+	for _, contractABI := range r.candidates(Hex2Address(strAddress)) {
+		for _, e := range contractABI.Errors {
+			if bytes.Equal(e.ID[:4], id[:4]) {
+				errDef := e
+				return &errDef, contractABI, nil
+			}
+		}
+	}
+	return nil, abi.ABI{}, fmt.Errorf("error id %x of contract [%s] not found", id[:4], strAddress)
+}
+
+// UnpackRevert decode revert data of Error(string), Panic(uint256) or a custom error of contract address
+func (r *ABIRegistry) UnpackRevert(strAddress string, data []byte) (string, error) {
+	reason, err := UnpackRevert(data)
+	if err == nil {
+		return reason, nil
+	}
+	var e *abi.Error
+	e, _, err = r.ErrorByID(strAddress, data)
+	if err != nil {
+		return "", err
+	}
+	var values interface{}
+	values, err = e.Unpack(data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%v", e.Name, values), nil
+}
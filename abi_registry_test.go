@@ -0,0 +1,122 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestABIRegistryProxy(t *testing.T) {
+	proxy, impl, other := "0x00000000000000000000000000000000000000a1", "0x00000000000000000000000000000000000000b1", "0x00000000000000000000000000000000000000c1"
+	registry := NewABIRegistry()
+	// an ABI registered to the zero address must not be taken as the implementation of unregistered proxies
+	registry.Register(NullAddress, mustLoadABI(overloadABI))
+	if _, ok := registry.ABI(other); ok {
+		t.Fatalf("unregistered contract resolved to the ABI of zero address")
+	}
+	registry.RegisterProxy(proxy, impl)
+	if _, ok := registry.ABI(proxy); ok {
+		t.Fatalf("proxy of unregistered implementation resolved to an ABI")
+	}
+	transfer := mustLoadABI(erc20TransferABI).Methods["transfer"].ID
+	if _, _, err := registry.MethodById(proxy, transfer); err == nil || !strings.Contains(err.Error(), Hex2Address(impl).String()) {
+		t.Fatalf("method of proxy without implementation ABI should fail naming the implementation, got %v", err)
+	}
+	registry.Register(impl, mustLoadABI(erc20TransferABI))
+	if contractABI, ok := registry.ABI(proxy); !ok || len(contractABI.Events) != 1 {
+		t.Fatalf("proxy not resolved to the ABI of implementation")
+	}
+	if method, _, err := registry.MethodById(proxy, transfer); err != nil || method.Sig != "transfer(address,uint256)" {
+		t.Fatalf("method of proxy error %v", err)
+	}
+	if _, _, err := registry.MethodById(other, transfer); err == nil {
+		t.Fatalf("method of other contract should not be found")
+	}
+}
+
+// fakeTxNode serve a transaction and its receipt
+type fakeTxNode struct {
+	tx      *types.Transaction
+	receipt *types.Receipt
+}
+
+func (f *fakeTxNode) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	return f.tx
+}
+
+func (f *fakeTxNode) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return f.receipt
+}
+
+func TestGetTxCallMethodProxy(t *testing.T) {
+	proxy, impl := common.HexToAddress("0xa1"), common.HexToAddress("0xb1")
+	contractABI := mustLoadABI(erc20TransferABI)
+	data, err := contractABI.Pack("transfer", proxy, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("pack transfer error %s", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 60000, To: &proxy, Value: new(big.Int), Data: data})
+	m := newTestClient(t, "eth", &fakeTxNode{tx: tx})
+	if _, err = m.GetTxCallMethod(context.Background(), tx.Hash().Hex()); err == nil {
+		t.Fatalf("call method of unresolved proxy should fail")
+	}
+	m.Registry.RegisterProxy(proxy.String(), impl.String())
+	m.Registry.Register(impl.String(), contractABI)
+	method, err := m.GetTxCallMethod(context.Background(), tx.Hash().Hex())
+	if err != nil {
+		t.Fatalf("get call method of proxy error %s", err)
+	}
+	if method.Name() != "transfer" || method.InputValues()[1].(*big.Int).Int64() != 100 {
+		t.Fatalf("call method of proxy decoded as %s %v", method.Prototype(), method.InputValues())
+	}
+}
+
+func TestGetTxCallMethodClientABI(t *testing.T) {
+	to := common.HexToAddress("0xa1")
+	contractABI := mustLoadABI(erc20TransferABI)
+	data, err := contractABI.Pack("transfer", to, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("pack transfer error %s", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 60000, To: &to, Value: new(big.Int), Data: data})
+	m := newTestClient(t, "eth", &fakeTxNode{tx: tx})
+	// the ABI assigned after the client is created is still used
+	m.ABI = contractABI
+	method, err := m.GetTxCallMethod(context.Background(), tx.Hash().Hex())
+	if err != nil || method.Name() != "transfer" {
+		t.Fatalf("get call method by client ABI got %v error %v", method, err)
+	}
+}
+
+func TestGetTxEventsProxy(t *testing.T) {
+	proxy, impl, other := common.HexToAddress("0xa1"), common.HexToAddress("0xb1"), common.HexToAddress("0xc1")
+	contractABI := mustLoadABI(erc20TransferABI)
+	tx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(1), Gas: 60000, To: &proxy, Value: new(big.Int)})
+	transfer := contractABI.Events["Transfer"].ID
+	amount := common.BigToHash(big.NewInt(100)).Bytes()
+	logs := []*types.Log{
+		{Address: proxy, Topics: []common.Hash{transfer, other.Hash(), proxy.Hash()}, Data: amount},
+		// the same event of another contract without registered ABI
+		{Address: other, Topics: []common.Hash{transfer, other.Hash(), proxy.Hash()}, Data: amount},
+	}
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: logs, TxHash: tx.Hash()}
+	m := newTestClient(t, "eth", &fakeTxNode{tx: tx, receipt: receipt})
+	m.Registry.RegisterProxy(proxy.String(), impl.String())
+	m.Registry.Register(impl.String(), contractABI)
+	events, err := m.GetTxEvents(context.Background(), tx.Hash().Hex())
+	if err != nil {
+		t.Fatalf("get tx events error %s", err)
+	}
+	if len(events) != 1 || events[0].Event.Name != "Transfer" || events[0].Log.Address != proxy {
+		t.Fatalf("events of proxy decoded as %v", events)
+	}
+	// the ABI of client decodes the logs of any contract
+	m.ABI = contractABI
+	if events, err = m.GetTxEvents(context.Background(), tx.Hash().Hex()); err != nil || len(events) != 2 {
+		t.Fatalf("get tx events by client ABI got %d events error %v", len(events), err)
+	}
+}
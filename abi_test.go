@@ -0,0 +1,120 @@
+package ethclient
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const openZeppelinArtifacts = "./contracts/@openzeppelin/contracts/build/contracts"
+
+const (
+	erc20TransferABI  = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+	erc721TransferABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+	overloadABI       = `[{"inputs":[{"name":"id","type":"uint256"}],"name":"transfer","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"name":"id","type":"uint256"},{"name":"data","type":"bytes"}],"name":"transfer","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"name":"code","type":"uint256"}],"name":"Failed","type":"error"}]`
+)
+
+func TestMergeABI(t *testing.T) {
+	merged := MergeABI(mustLoadABI(erc20TransferABI), mustLoadABI(erc721TransferABI), mustLoadABI(overloadABI))
+
+	// the same transfer(address,uint256) of both contracts is merged, the overloads of the third are renamed
+	wantMethods := map[string]string{
+		"transfer":   "transfer(address,uint256)",
+		"transfer0":  "transfer(uint256)",
+		"transfer00": "transfer(uint256,bytes)",
+	}
+	if len(merged.Methods) != len(wantMethods) {
+		t.Fatalf("merged %d methods, want %d", len(merged.Methods), len(wantMethods))
+	}
+	for name, sig := range wantMethods {
+		method, ok := merged.Methods[name]
+		if !ok || method.Sig != sig {
+			t.Fatalf("merged method [%s] is %q, want %q", name, method.Sig, sig)
+		}
+		if method.Name != name {
+			t.Errorf("merged method [%s] has name %s", name, method.Name)
+		}
+		byId, err := merged.MethodById(method.ID)
+		if err != nil || byId.Sig != sig {
+			t.Fatalf("method by id of %s error %v", sig, err)
+		}
+	}
+	// the name of method looked up by id packs the same overload
+	byId, _ := merged.MethodById(merged.Methods["transfer00"].ID)
+	data, err := merged.Pack(byId.Name, big.NewInt(1), []byte{1})
+	if err != nil || !bytes.Equal(data[:4], byId.ID) {
+		t.Fatalf("pack method [%s] error %v", byId.Name, err)
+	}
+
+	// Transfer of ERC20 and ERC721 share the topic but not the indexed arguments, both are kept
+	if len(merged.Events) != 2 {
+		t.Fatalf("merged %d events, want 2", len(merged.Events))
+	}
+	topic := merged.Events["Transfer"].ID
+	from, to := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})
+	erc20, err := EventByLog(merged, []common.Hash{topic, from, to})
+	if err != nil || erc20.Inputs[2].Indexed {
+		t.Fatalf("event of 3 topics should be ERC20 Transfer, got %v error %v", erc20, err)
+	}
+	erc721, err := EventByLog(merged, []common.Hash{topic, from, to, common.BytesToHash([]byte{3})})
+	if err != nil || !erc721.Inputs[2].Indexed || erc721.Name != "Transfer0" {
+		t.Fatalf("event of 4 topics should be ERC721 Transfer, got %v error %v", erc721, err)
+	}
+	if _, err = EventByLog(merged, []common.Hash{topic}); err == nil {
+		t.Errorf("event of 1 topic should not be found")
+	}
+	if _, ok := merged.Errors["Failed"]; !ok {
+		t.Errorf("error Failed not merged")
+	}
+
+	// merging the same ABI twice changes nothing
+	again := MergeABI(merged, merged)
+	if len(again.Methods) != len(merged.Methods) || len(again.Events) != len(merged.Events) {
+		t.Errorf("merge of same ABI added entries")
+	}
+}
+
+func TestLoadABIDirectory(t *testing.T) {
+	contractABI, err := LoadABI(openZeppelinArtifacts)
+	if err != nil {
+		t.Fatalf("load abi of directory error %s", err)
+	}
+	// the ERC721 transfer of the repo's test tx must still decode with the merged ABI
+	topic := contractABI.Events["Transfer"].ID
+	event, err := EventByLog(contractABI, []common.Hash{topic, {}, {}, common.BigToHash(common.Big1)})
+	if err != nil {
+		t.Fatalf("lookup ERC721 Transfer error %s", err)
+	}
+	if len(event.Inputs) != 3 || !event.Inputs[2].Indexed || event.Inputs[2].Name != "tokenId" {
+		t.Fatalf("ERC721 Transfer resolved to %s", event.String())
+	}
+	if event, err = EventByLog(contractABI, []common.Hash{topic, {}, {}}); err != nil || event.Inputs[2].Name != "value" {
+		t.Fatalf("ERC20 Transfer resolved to %v error %v", event, err)
+	}
+	for name, method := range contractABI.Methods {
+		if method.Name != name {
+			t.Errorf("method key [%s] has name %s", name, method.Name)
+		}
+	}
+}
+
+func TestUnpackRevertPanic(t *testing.T) {
+	tests := []struct {
+		code uint64
+		want string
+	}{
+		{0x01, "panic: assert(false) (0x1)"},
+		{0x31, "panic: pop() on an empty array (0x31)"},
+		{0x32, "panic: out-of-bounds or negative index access of an array, bytesN or array slice (0x32)"},
+		{0x99, "panic: unknown code 0x99"},
+	}
+	for _, tt := range tests {
+		data := append(common.CopyBytes(panicSelector), common.BigToHash(new(big.Int).SetUint64(tt.code)).Bytes()...)
+		reason, err := UnpackRevert(data)
+		if err != nil || reason != tt.want {
+			t.Errorf("unpack panic 0x%x got %q error %v, want %q", tt.code, reason, err, tt.want)
+		}
+	}
+}
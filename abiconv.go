@@ -0,0 +1,258 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ConvertArgs convert loosely typed values to the go types of abi arguments for packing, see ConvertArg
+func ConvertArgs(args abi.Arguments, values ...interface{}) ([]interface{}, error) {
+	if len(args) != len(values) {
+		return nil, fmt.Errorf("argument count mismatch: got %d for %d", len(values), len(args))
+	}
+	out := make([]interface{}, len(values))
+	for i, arg := range args {
+		v, err := ConvertArg(arg.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument [%s] %s", arg.Name, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// ConvertArg convert a loosely typed value to the go type of abi type. Integers accept go numbers, *big.Int and
+// decimal or 0x hex strings, addresses and bytes accept hex strings, arrays accept slices or JSON array strings and
+// tuples accept slices in field order, maps by field name or JSON strings. Values of the exact go type pass through
+func ConvertArg(typ abi.Type, value interface{}) (interface{}, error) {
+	v, err := convertArg(typ, value)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func convertArg(typ abi.Type, value interface{}) (reflect.Value, error) {
+	goType := typ.GetType()
+	if value != nil && reflect.TypeOf(value) == goType {
+		return reflect.ValueOf(value), nil
+	}
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := convertBig(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if err = checkIntRange(typ, n); err != nil {
+			return reflect.Value{}, err
+		}
+		rv := reflect.New(goType).Elem()
+		switch goType.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			rv.SetInt(n.Int64())
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			rv.SetUint(n.Uint64())
+		default:
+			rv.Set(reflect.ValueOf(n))
+		}
+		return rv, nil
+	case abi.BoolTy:
+		switch b := value.(type) {
+		case bool:
+			return reflect.ValueOf(b), nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("value %q is not a bool", b)
+			}
+			return reflect.ValueOf(parsed), nil
+		}
+	case abi.StringTy:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s), nil
+		}
+		return reflect.ValueOf(fmt.Sprint(value)), nil
+	case abi.AddressTy:
+		if s, ok := value.(string); ok {
+			if !common.IsHexAddress(s) {
+				return reflect.Value{}, fmt.Errorf("value %q is not an address", s)
+			}
+			return reflect.ValueOf(common.HexToAddress(s)), nil
+		}
+	case abi.BytesTy:
+		if s, ok := value.(string); ok {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("value %q is not hex bytes", s)
+			}
+			return reflect.ValueOf(b), nil
+		}
+	case abi.FixedBytesTy, abi.FunctionTy:
+		var b []byte
+		switch s := value.(type) {
+		case string:
+			var err error
+			if b, err = hexutil.Decode(s); err != nil {
+				return reflect.Value{}, fmt.Errorf("value %q is not hex bytes", s)
+			}
+		case []byte:
+			b = s
+		case common.Hash:
+			b = s.Bytes()
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, typ)
+		}
+		if len(b) != goType.Len() {
+			return reflect.Value{}, fmt.Errorf("value 0x%x length %d mismatch with %s", b, len(b), typ)
+		}
+		rv := reflect.New(goType).Elem()
+		reflect.Copy(rv, reflect.ValueOf(b))
+		return rv, nil
+	case abi.SliceTy, abi.ArrayTy:
+		items, err := convertList(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var rv reflect.Value
+		if typ.T == abi.ArrayTy {
+			if len(items) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("array length %d mismatch with %s", len(items), typ)
+			}
+			rv = reflect.New(goType).Elem()
+		} else {
+			rv = reflect.MakeSlice(goType, len(items), len(items))
+		}
+		for i, item := range items {
+			ev, err := convertArg(*typ.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d %s", i, err)
+			}
+			rv.Index(i).Set(ev)
+		}
+		return rv, nil
+	case abi.TupleTy:
+		return convertTuple(typ, value)
+	}
+	return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, typ)
+}
+
+func convertTuple(typ abi.Type, value interface{}) (reflect.Value, error) {
+	if s, ok := value.(string); ok {
+		var decoded interface{}
+		if err := unmarshalJSONNumber(s, &decoded); err != nil {
+			return reflect.Value{}, fmt.Errorf("value %q is not a JSON tuple", s)
+		}
+		value = decoded
+	}
+	rv := reflect.New(typ.GetType()).Elem()
+	switch fields := value.(type) {
+	case []interface{}:
+		if len(fields) != len(typ.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("tuple length %d mismatch with %s", len(fields), typ)
+		}
+		for i, elem := range typ.TupleElems {
+			fv, err := convertArg(*elem, fields[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s %s", typ.TupleRawNames[i], err)
+			}
+			rv.Field(i).Set(fv)
+		}
+	case map[string]interface{}:
+		for i, elem := range typ.TupleElems {
+			name := typ.TupleRawNames[i]
+			field, ok := fields[name]
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("field %s missing", name)
+			}
+			fv, err := convertArg(*elem, field)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s %s", name, err)
+			}
+			rv.Field(i).Set(fv)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, typ)
+	}
+	return rv, nil
+}
+
+// convertBig convert go numbers, *big.Int, json.Number and decimal or 0x hex strings into *big.Int
+func convertBig(value interface{}) (*big.Int, error) {
+	switch n := value.(type) {
+	case string:
+		s := strings.TrimSpace(n)
+		neg := strings.HasPrefix(s, "-")
+		s = strings.TrimPrefix(s, "-")
+		base := 10
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			s, base = s[2:], 16
+		}
+		bigValue, ok := new(big.Int).SetString(s, base)
+		if !ok {
+			return nil, fmt.Errorf("value %q is not an integer", n)
+		}
+		if neg {
+			bigValue.Neg(bigValue)
+		}
+		return bigValue, nil
+	case json.Number:
+		return convertBig(n.String())
+	case float64:
+		if n != float64(int64(n)) {
+			return nil, fmt.Errorf("value %v is not an integer", n)
+		}
+		return big.NewInt(int64(n)), nil
+	}
+	return parseBigValue(value)
+}
+
+// checkIntRange check n fits in the int or uint type
+func checkIntRange(typ abi.Type, n *big.Int) error {
+	if typ.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > typ.Size {
+			return fmt.Errorf("value %s overflows %s", n, typ)
+		}
+		return nil
+	}
+	// int range is [-2^(size-1), 2^(size-1)-1]
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+	if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("value %s overflows %s", n, typ)
+	}
+	return nil
+}
+
+// convertList returns the items of a slice, array or JSON array string
+func convertList(value interface{}) ([]interface{}, error) {
+	if s, ok := value.(string); ok {
+		var items []interface{}
+		if err := unmarshalJSONNumber(s, &items); err != nil {
+			return nil, fmt.Errorf("value %q is not a JSON array", s)
+		}
+		return items, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("cannot convert %T to array", value)
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// unmarshalJSONNumber unmarshal JSON keeping numbers as json.Number to not lose precision
+func unmarshalJSONNumber(s string, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,123 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func mustNewType(t *testing.T, typ string, components ...abi.ArgumentMarshaling) abi.Type {
+	ty, err := abi.NewType(typ, "", components)
+	if err != nil {
+		t.Fatalf("new type %s error %s", typ, err)
+	}
+	return ty
+}
+
+func TestConvertArg(t *testing.T) {
+	pair := []abi.ArgumentMarshaling{{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}}
+	addr := "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	tests := []struct {
+		typ   abi.Type
+		value interface{}
+		want  string // printed value of the converted go type
+	}{
+		{mustNewType(t, "uint256"), "1000000000000000000000", "1000000000000000000000"},
+		{mustNewType(t, "uint256"), "0xff", "255"},
+		{mustNewType(t, "uint256"), 7, "7"},
+		{mustNewType(t, "uint256"), big.NewInt(9), "9"},
+		{mustNewType(t, "uint256"), float64(3), "3"},
+		{mustNewType(t, "uint256"), json.Number("12345678901234567890123"), "12345678901234567890123"},
+		{mustNewType(t, "uint8"), "255", "255"},
+		{mustNewType(t, "uint64"), " 42 ", "42"},
+		{mustNewType(t, "int8"), "-128", "-128"},
+		{mustNewType(t, "int32"), "-0x10", "-16"},
+		{mustNewType(t, "int256"), "-1", "-1"},
+		{mustNewType(t, "bool"), "true", "true"},
+		{mustNewType(t, "bool"), false, "false"},
+		{mustNewType(t, "string"), "hello", "hello"},
+		{mustNewType(t, "string"), 12, "12"},
+		{mustNewType(t, "address"), addr, addr},
+		{mustNewType(t, "address"), common.HexToAddress(addr), addr},
+		{mustNewType(t, "bytes"), "0x0102", "[1 2]"},
+		{mustNewType(t, "bytes4"), "0xa9059cbb", "[169 5 156 187]"},
+		{mustNewType(t, "bytes32"), common.BigToHash(big.NewInt(1)), fmt.Sprint(common.BigToHash(big.NewInt(1)).Bytes())},
+		{mustNewType(t, "uint16[]"), []interface{}{1, "2", "0x3"}, "[1 2 3]"},
+		{mustNewType(t, "uint16[]"), "[1, 2, 3]", "[1 2 3]"},
+		{mustNewType(t, "address[2]"), []string{addr, addr}, "[" + addr + " " + addr + "]"},
+		{mustNewType(t, "uint256[][]"), "[[1], [2, 3]]", "[[1] [2 3]]"},
+		{mustNewType(t, "tuple", pair...), []interface{}{addr, "5"}, "{" + addr + " 5}"},
+		{mustNewType(t, "tuple", pair...), map[string]interface{}{"to": addr, "amount": 5}, "{" + addr + " 5}"},
+		{mustNewType(t, "tuple", pair...), `{"to": "` + addr + `", "amount": 123456789012345678901234567890}`, "{" + addr + " 123456789012345678901234567890}"},
+		{mustNewType(t, "tuple[]", pair...), `[["` + addr + `", 1], {"to": "` + addr + `", "amount": 2}]`, "[{" + addr + " 1} {" + addr + " 2}]"},
+	}
+	for _, tt := range tests {
+		got, err := ConvertArg(tt.typ, tt.value)
+		if err != nil {
+			t.Fatalf("convert %v to %s error %s", tt.value, tt.typ, err)
+		}
+		if reflect.TypeOf(got) != tt.typ.GetType() {
+			t.Errorf("convert %v to %s got go type %T, want %s", tt.value, tt.typ, got, tt.typ.GetType())
+		}
+		if s := fmt.Sprint(got); s != tt.want {
+			t.Errorf("convert %v to %s got %s, want %s", tt.value, tt.typ, s, tt.want)
+		}
+		// the converted value must be packable
+		if _, err = (abi.Arguments{{Type: tt.typ}}).Pack(got); err != nil {
+			t.Errorf("pack converted %v of %s error %s", tt.value, tt.typ, err)
+		}
+	}
+}
+
+func TestConvertArgError(t *testing.T) {
+	pair := []abi.ArgumentMarshaling{{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}}
+	tests := []struct {
+		typ   abi.Type
+		value interface{}
+	}{
+		{mustNewType(t, "uint8"), 256},
+		{mustNewType(t, "uint256"), -1},
+		{mustNewType(t, "uint256"), "1.5"},
+		{mustNewType(t, "uint256"), float64(1.5)},
+		{mustNewType(t, "int8"), 128},
+		{mustNewType(t, "int8"), -129},
+		{mustNewType(t, "bool"), "yes"},
+		{mustNewType(t, "bool"), 1},
+		{mustNewType(t, "address"), "0x1234"},
+		{mustNewType(t, "address"), 1},
+		{mustNewType(t, "bytes"), "0102"},
+		{mustNewType(t, "bytes4"), "0x010203"},
+		{mustNewType(t, "bytes4"), 1},
+		{mustNewType(t, "uint16[]"), "1,2"},
+		{mustNewType(t, "uint16[]"), []interface{}{1, "x"}},
+		{mustNewType(t, "uint16[2]"), []interface{}{1}},
+		{mustNewType(t, "tuple", pair...), []interface{}{"0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"}},
+		{mustNewType(t, "tuple", pair...), map[string]interface{}{"to": "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"}},
+		{mustNewType(t, "tuple", pair...), "not json"},
+		{mustNewType(t, "tuple", pair...), 1},
+	}
+	for _, tt := range tests {
+		if got, err := ConvertArg(tt.typ, tt.value); err == nil {
+			t.Errorf("convert %v (%T) to %s should fail, got %v", tt.value, tt.value, tt.typ, got)
+		}
+	}
+}
+
+func TestConvertArgs(t *testing.T) {
+	method := mustLoadABI(erc20TransferABI).Methods["transfer"]
+	values, err := ConvertArgs(method.Inputs, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", "1000")
+	if err != nil {
+		t.Fatalf("convert args error %s", err)
+	}
+	if _, err = method.Inputs.Pack(values...); err != nil {
+		t.Fatalf("pack converted args error %s", err)
+	}
+	if _, err = ConvertArgs(method.Inputs, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"); err == nil {
+		t.Errorf("convert args of wrong count should fail")
+	}
+}
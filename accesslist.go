@@ -0,0 +1,37 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CreateAccessList generate the EIP-2930 access list of the call message at pending state by eth_createAccessList,
+// gasUsed is the gas used by the call with the access list attached
+func (m *EthereumClient) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (accessList *types.AccessList, gasUsed uint64, err error) {
+	type accessListResult struct {
+		AccessList *types.AccessList `json:"accessList"`
+		Error      string            `json:"error,omitempty"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	}
+	var result accessListResult
+	if err = m.ethcli.Client().CallContext(ctx, &result, "eth_createAccessList", toCallArg(msg), "pending"); err != nil {
+		return nil, 0, err
+	}
+	if result.Error != "" {
+		return nil, 0, fmt.Errorf("create access list error [%s]", result.Error)
+	}
+	return result.AccessList, uint64(result.GasUsed), nil
+}
+
+// estimateGas eth_estimateGas with the access list of msg which ethclient drops
+func (m *EthereumClient) estimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var hex hexutil.Uint64
+	if err := m.ethcli.Client().CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
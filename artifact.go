@@ -0,0 +1,254 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	jsonFileSuffix = ".json"
+	// hardhatDebugSuffix is the suffix of Hardhat debug files beside the artifacts
+	hardhatDebugSuffix = ".dbg.json"
+	// buildInfoDir is the directory of compiler input and output of Hardhat and Foundry, not artifacts
+	buildInfoDir = "build-info"
+)
+
+// ContractArtifact is a compiled contract of a Hardhat or Foundry artifact or solc --combined-json output
+type ContractArtifact struct {
+	Name              string
+	ABI               abi.ABI
+	Bytecode          string // creation bytecode hex, may contain library placeholders
+	DeployedBytecode  string // runtime bytecode hex, may contain library placeholders
+	LinkReferences    LinkReferences
+	MethodIdentifiers map[string]string // method signature => 4 bytes selector hex, e.g. transfer(address,uint256) => a9059cbb
+}
+
+// LinkReferences is the library placeholder positions of bytecode by source file and library name
+type LinkReferences map[string]map[string][]LinkReference
+
+// LinkReference is the byte position of a library address in bytecode
+type LinkReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// artifactBytecode is the bytecode of Hardhat (hex string) or Foundry ({"object": hex, "linkReferences": ...}) artifacts
+type artifactBytecode struct {
+	Object         string         `json:"object"`
+	LinkReferences LinkReferences `json:"linkReferences"`
+}
+
+func (b *artifactBytecode) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &b.Object)
+	}
+	type bytecode artifactBytecode
+	return json.Unmarshal(data, (*bytecode)(b))
+}
+
+// LoadArtifact load a Hardhat or Foundry contract artifact from JSON file or string
+func LoadArtifact(strArtifact string) (*ContractArtifact, error) {
+	artifacts, err := LoadArtifacts(strArtifact)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifacts) != 1 {
+		return nil, fmt.Errorf("%d contracts found in artifact, use LoadArtifacts", len(artifacts))
+	}
+	return artifacts[0], nil
+}
+
+// LoadArtifacts load the contracts of a Hardhat or Foundry artifact, a solc --combined-json output or a raw ABI
+// from JSON string or file, or of all the artifacts under a directory (e.g. artifacts/ of Hardhat or out/ of Foundry)
+func LoadArtifacts(strPath string) ([]*ContractArtifact, error) {
+	if s := strings.TrimSpace(strPath); strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+		return parseArtifacts([]byte(strPath), "")
+	}
+	info, err := os.Stat(strPath)
+	if err != nil {
+		return nil, fmt.Errorf("read artifact file %s error: %s", strPath, err.Error())
+	}
+	if !info.IsDir() {
+		return loadArtifactFile(strPath)
+	}
+	var artifacts []*ContractArtifact
+	err = filepath.WalkDir(strPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == buildInfoDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, hardhatDebugSuffix) ||
+			(!strings.HasSuffix(path, jsonFileSuffix) && !strings.HasSuffix(path, abiFileSuffix)) {
+			return nil
+		}
+		contracts, err := loadArtifactFile(path)
+		if err != nil {
+			// other JSON files (caches, configs) may be mixed with the artifacts
+			return nil
+		}
+		artifacts = append(artifacts, contracts...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk artifact directory %s error: %s", strPath, err.Error())
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("no contract artifact found in directory %s", strPath)
+	}
+	return artifacts, nil
+}
+
+// loadArtifactFile load artifacts from file, Foundry artifacts have no contract name and are named by file name
+func loadArtifactFile(path string) ([]*ContractArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read artifact file %s error: %s", path, err.Error())
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return parseArtifacts(data, name)
+}
+
+// parseArtifacts parse a contract artifact, a solc --combined-json output or a raw ABI
+func parseArtifacts(data []byte, name string) ([]*ContractArtifact, error) {
+	if s := strings.TrimSpace(string(data)); strings.HasPrefix(s, "[") {
+		contractABI, err := loadABIFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return []*ContractArtifact{newContractArtifact(name, contractABI)}, nil
+	}
+	var artifact struct {
+		ContractName     string                     `json:"contractName"`
+		ABI              json.RawMessage            `json:"abi"`
+		Bytecode         artifactBytecode           `json:"bytecode"`
+		DeployedBytecode artifactBytecode           `json:"deployedBytecode"`
+		LinkReferences   LinkReferences             `json:"linkReferences"`
+		Contracts        map[string]json.RawMessage `json:"contracts"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, fmt.Errorf("unmarshal artifact json error: %s", err.Error())
+	}
+	if len(artifact.ABI) == 0 {
+		if artifact.Contracts != nil {
+			return parseCombinedJSON(artifact.Contracts)
+		}
+		return nil, fmt.Errorf("artifact has no abi")
+	}
+	contractABI, err := loadABIFromString(string(artifact.ABI))
+	if err != nil {
+		return nil, err
+	}
+	if artifact.ContractName != "" {
+		name = artifact.ContractName
+	}
+	refs := artifact.LinkReferences
+	if refs == nil {
+		refs = artifact.Bytecode.LinkReferences
+	}
+	contract := newContractArtifact(name, contractABI)
+	contract.Bytecode = TrimHexPrefix(artifact.Bytecode.Object)
+	contract.DeployedBytecode = TrimHexPrefix(artifact.DeployedBytecode.Object)
+	contract.LinkReferences = refs
+	return []*ContractArtifact{contract}, nil
+}
+
+// parseCombinedJSON parse the contracts of solc --combined-json abi,bin,bin-runtime output keyed by path:Name,
+// the abi is a JSON string before solc 0.8
+func parseCombinedJSON(contracts map[string]json.RawMessage) ([]*ContractArtifact, error) {
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	artifacts := make([]*ContractArtifact, 0, len(names))
+	for _, name := range names {
+		var contract struct {
+			ABI        json.RawMessage `json:"abi"`
+			Bin        string          `json:"bin"`
+			BinRuntime string          `json:"bin-runtime"`
+		}
+		if err := json.Unmarshal(contracts[name], &contract); err != nil {
+			return nil, fmt.Errorf("unmarshal combined json contract [%s] error: %s", name, err.Error())
+		}
+		strABI := string(contract.ABI)
+		if strings.HasPrefix(strABI, `"`) {
+			if err := json.Unmarshal(contract.ABI, &strABI); err != nil {
+				return nil, fmt.Errorf("unmarshal combined json contract [%s] abi error: %s", name, err.Error())
+			}
+		}
+		if strABI == "" {
+			return nil, fmt.Errorf("combined json contract [%s] has no abi, compile with --combined-json abi", name)
+		}
+		contractABI, err := loadABIFromString(strABI)
+		if err != nil {
+			return nil, err
+		}
+		artifact := newContractArtifact(name[strings.LastIndex(name, ":")+1:], contractABI)
+		artifact.Bytecode = TrimHexPrefix(contract.Bin)
+		artifact.DeployedBytecode = TrimHexPrefix(contract.BinRuntime)
+		artifacts = append(artifacts, artifact)
+	}
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("combined json has no contracts")
+	}
+	return artifacts, nil
+}
+
+func newContractArtifact(name string, contractABI abi.ABI) *ContractArtifact {
+	identifiers := make(map[string]string, len(contractABI.Methods))
+	for _, method := range contractABI.Methods {
+		identifiers[method.Sig] = common.Bytes2Hex(method.ID)
+	}
+	return &ContractArtifact{
+		Name:              name,
+		ABI:               contractABI,
+		MethodIdentifiers: identifiers,
+	}
+}
+
+// LinkBytecode returns the creation bytecode with the libraries linked, the libraries are keyed by name or fully
+// qualified name (path/to/Lib.sol:Lib) and located by the link references of artifact
+func (m *ContractArtifact) LinkBytecode(libraries map[string]string) (string, error) {
+	code := []byte(m.Bytecode)
+	files := make([]string, 0, len(m.LinkReferences))
+	for file := range m.LinkReferences {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		for name, positions := range m.LinkReferences[file] {
+			addr, ok := libraries[file+":"+name]
+			if !ok {
+				addr, ok = libraries[name]
+			}
+			if !ok {
+				continue
+			}
+			if !common.IsHexAddress(addr) {
+				return "", fmt.Errorf("library [%s] address [%s] invalid", name, addr)
+			}
+			strAddr := strings.ToLower(TrimHexPrefix(common.HexToAddress(addr).Hex()))
+			for _, pos := range positions {
+				if pos.Length != common.AddressLength || 2*(pos.Start+pos.Length) > len(code) {
+					return "", fmt.Errorf("library [%s] link reference %d out of bytecode", name, pos.Start)
+				}
+				copy(code[2*pos.Start:], strAddr)
+			}
+		}
+	}
+	// placeholders without link references are linked by name
+	return LinkBytecode(string(code), libraries)
+}
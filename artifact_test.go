@@ -0,0 +1,109 @@
+package ethclient
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const (
+	hardhatArtifact = `{"_format":"hh-sol-artifact-1","contractName":"Token","sourceName":"contracts/Token.sol",
+"abi":` + erc20TransferABI + `,"bytecode":"0x6080__$a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5$__00","deployedBytecode":"0x6001",
+"linkReferences":{"contracts/Lib.sol":{"Lib":[{"start":2,"length":20}]}},"deployedLinkReferences":{}}`
+	foundryArtifact = `{"abi":` + erc721TransferABI + `,"bytecode":{"object":"0x6002","linkReferences":{}},
+"deployedBytecode":{"object":"0x6003","linkReferences":{}},"methodIdentifiers":{"transfer(address,uint256)":"a9059cbb"}}`
+)
+
+func TestLoadArtifacts(t *testing.T) {
+	// abi as JSON string before solc 0.8, as array since 0.8
+	combined := `{"contracts":{"b.sol:B":{"abi":` + strconv.Quote(overloadABI) + `,"bin":"6004","bin-runtime":"6005"},
+"a.sol:A":{"abi":` + erc20TransferABI + `,"bin":"0x6006","bin-runtime":"6007"}},"version":"0.7.6"}`
+	tests := []struct {
+		name      string
+		json      string
+		contracts []string // name:bytecode:deployed bytecode
+	}{
+		{"hardhat", hardhatArtifact, []string{"Token:6080__$a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5$__00:6001"}},
+		{"foundry", foundryArtifact, []string{":6002:6003"}},
+		{"combined json", combined, []string{"A:6006:6007", "B:6004:6005"}},
+		{"raw abi", erc20TransferABI, []string{"::"}},
+	}
+	for _, tt := range tests {
+		artifacts, err := LoadArtifacts(tt.json)
+		if err != nil {
+			t.Fatalf("%s: load artifacts error %s", tt.name, err)
+		}
+		if len(artifacts) != len(tt.contracts) {
+			t.Fatalf("%s: loaded %d contracts, want %d", tt.name, len(artifacts), len(tt.contracts))
+		}
+		for i, want := range tt.contracts {
+			a := artifacts[i]
+			if got := a.Name + ":" + a.Bytecode + ":" + a.DeployedBytecode; got != want {
+				t.Errorf("%s: contract %d is %s, want %s", tt.name, i, got, want)
+			}
+			if len(a.MethodIdentifiers) != len(a.ABI.Methods) {
+				t.Errorf("%s: %d method identifiers of %d methods", tt.name, len(a.MethodIdentifiers), len(a.ABI.Methods))
+			}
+		}
+	}
+
+	artifacts, _ := LoadArtifacts(hardhatArtifact)
+	if id := artifacts[0].MethodIdentifiers["transfer(address,uint256)"]; id != "a9059cbb" {
+		t.Errorf("method identifier of transfer is %s", id)
+	}
+	if refs := artifacts[0].LinkReferences["contracts/Lib.sol"]["Lib"]; len(refs) != 1 || refs[0].Start != 2 {
+		t.Errorf("link references %v", artifacts[0].LinkReferences)
+	}
+	if _, err := LoadArtifact(combined); err == nil {
+		t.Errorf("load one artifact of combined json of 2 contracts should fail")
+	}
+	for _, s := range []string{`{"contracts":{}}`, `{"bytecode":"0x00"}`, `{"abi":[{"type":"bogus"}]}`} {
+		if _, err := LoadArtifacts(s); err == nil {
+			t.Errorf("load artifacts of %s should fail", s)
+		}
+	}
+}
+
+func TestLoadArtifactsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"artifacts/contracts/Token.sol/Token.json":     hardhatArtifact,
+		"artifacts/contracts/Token.sol/Token.dbg.json": `{"_format":"hh-sol-dbg-1","buildInfo":"../../build-info/x.json"}`,
+		"artifacts/build-info/x.json":                  `{"output":{"contracts":{}}}`,
+		"out/NFT.sol/NFT.json":                         foundryArtifact,
+		"out/cache.json":                               `{"files":{}}`,
+		"abis/IERC165.abi":                             `[{"inputs":[{"name":"interfaceId","type":"bytes4"}],"name":"supportsInterface","outputs":[{"type":"bool"}],"stateMutability":"view","type":"function"}]`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	artifacts, err := LoadArtifacts(dir)
+	if err != nil {
+		t.Fatalf("load artifacts of directory error %s", err)
+	}
+	names := map[string]bool{}
+	for _, a := range artifacts {
+		names[a.Name] = true
+	}
+	// debug files, build info and other JSON files are skipped, Foundry and raw ABIs are named by file
+	if len(artifacts) != 3 || !names["Token"] || !names["NFT"] || !names["IERC165"] {
+		t.Fatalf("loaded contracts %v", names)
+	}
+	if _, err = LoadArtifacts(filepath.Join(dir, "artifacts", "build-info")); err == nil {
+		t.Errorf("load directory without artifacts should fail")
+	}
+	contractABI, err := LoadABI(filepath.Join(dir, "out", "NFT.sol", "NFT.json"))
+	if err != nil || len(contractABI.Events) != 1 {
+		t.Fatalf("load abi of foundry artifact file error %v", err)
+	}
+	if contractABI, err = LoadABI(dir); err != nil || len(contractABI.Events) != 2 {
+		t.Fatalf("load abi of directory got %d events error %v", len(contractABI.Events), err)
+	}
+}
@@ -0,0 +1,119 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	blockTimeCacheSize = 4096
+	// headers deeper than blockTimeConfirmations below the latest head are cached as they will not reorg
+	blockTimeConfirmations = 64
+)
+
+// BlockAtTime returns the header of the last block with timestamp at or before t
+func (m *EthereumClient) BlockAtTime(ctx context.Context, t time.Time) (*types.Header, error) {
+	ts := uint64(t.Unix())
+	n, err := m.searchBlockByTime(ctx, ts, true)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no block at or before %s", t.UTC().Format(time.RFC3339))
+	}
+	return m.cachedHeaderByNumber(ctx, n-1, 0)
+}
+
+// FirstBlockAtTime returns the header of the first block with timestamp at or after t
+func (m *EthereumClient) FirstBlockAtTime(ctx context.Context, t time.Time) (*types.Header, error) {
+	n, err := m.searchBlockByTime(ctx, uint64(t.Unix()), false)
+	if err != nil {
+		return nil, err
+	}
+	return m.cachedHeaderByNumber(ctx, n, 0)
+}
+
+// searchBlockByTime returns the smallest block number whose timestamp is after ts (strict) or at or after ts,
+// it narrows the range by interpolating the timestamps and falls back to bisection for the next step when an
+// interpolation step does not halve the range (e.g. around missed slots or a change of block time)
+func (m *EthereumClient) searchBlockByTime(ctx context.Context, ts uint64, strict bool) (uint64, error) {
+	match := func(h *types.Header) bool {
+		if strict {
+			return h.Time > ts
+		}
+		return h.Time >= ts
+	}
+	latest, err := m.ethcli.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("get latest header error [%s]", err)
+	}
+	head := latest.Number.Uint64()
+	if !match(latest) {
+		if strict {
+			// every block is at or before ts
+			return head + 1, nil
+		}
+		return 0, fmt.Errorf("no block at or after %d, latest block [%d] time is %d", ts, head, latest.Time)
+	}
+	var lo *types.Header
+	lo, err = m.cachedHeaderByNumber(ctx, 0, head)
+	if err != nil {
+		return 0, err
+	}
+	if match(lo) {
+		return 0, nil
+	}
+	// invariant: !match(lo) && match(hi)
+	hi := latest
+	for bisect := false; hi.Number.Uint64()-lo.Number.Uint64() > 1; {
+		loNum, hiNum := lo.Number.Uint64(), hi.Number.Uint64()
+		next := loNum + (hiNum-loNum)/2
+		if !bisect && hi.Time > lo.Time {
+			target := ts
+			if strict {
+				target++
+			}
+			span := new(big.Int).SetUint64(hiNum - loNum)
+			offset := new(big.Int).Mul(span, new(big.Int).SetUint64(target-lo.Time))
+			offset.Div(offset, new(big.Int).SetUint64(hi.Time-lo.Time))
+			next = loNum + offset.Uint64()
+		}
+		if next <= loNum {
+			next = loNum + 1
+		} else if next >= hiNum {
+			next = hiNum - 1
+		}
+		var h *types.Header
+		h, err = m.cachedHeaderByNumber(ctx, next, head)
+		if err != nil {
+			return 0, err
+		}
+		if match(h) {
+			hi = h
+		} else {
+			lo = h
+		}
+		// interpolation stalls when the timestamps are not linear in the range
+		bisect = !bisect && 2*(hi.Number.Uint64()-lo.Number.Uint64()) > hiNum-loNum
+	}
+	return hi.Number.Uint64(), nil
+}
+
+// cachedHeaderByNumber returns header by number from the block time cache, headers confirmed below head are cached
+func (m *EthereumClient) cachedHeaderByNumber(ctx context.Context, number, head uint64) (*types.Header, error) {
+	if h, ok := m.timeHeaders.Get(number); ok {
+		return h, nil
+	}
+	h, err := m.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("get header by number [%d] error [%s]", number, err)
+	}
+	if number+blockTimeConfirmations <= head {
+		m.timeHeaders.Add(number, h)
+	}
+	return h, nil
+}
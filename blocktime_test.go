@@ -0,0 +1,109 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testGenesisTime = 1600000000
+
+// fakeHeaderNode serve the headers of a chain of blocks every 12 seconds, with 10 missed slots before block 500
+// and blocks 800 and 801 of the same timestamp
+type fakeHeaderNode struct {
+	head    uint64
+	fetches int
+}
+
+func testBlockTime(n uint64) uint64 {
+	ts := testGenesisTime + 12*n
+	if n >= 500 {
+		ts += 120
+	}
+	if n >= 801 {
+		ts -= 12
+	}
+	return ts
+}
+
+func (f *fakeHeaderNode) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	f.fetches++
+	n := f.head
+	if number != "latest" {
+		v, err := hexutil.DecodeUint64(number)
+		if err != nil {
+			return nil, err
+		}
+		n = v
+	}
+	if n > f.head {
+		return nil, nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n), Time: testBlockTime(n), Difficulty: new(big.Int)}, nil
+}
+
+func TestBlockAtTime(t *testing.T) {
+	node := &fakeHeaderNode{head: 1000}
+	m := newTestClient(t, "eth", node)
+	ctx := context.Background()
+	at := func(ts uint64) time.Time { return time.Unix(int64(ts), 0) }
+	tests := []struct {
+		ts             uint64
+		atOrBefore     uint64
+		firstAtOrAfter uint64
+	}{
+		{testBlockTime(0), 0, 0},
+		{testBlockTime(1), 1, 1},
+		{testBlockTime(1) + 1, 1, 2},
+		{testBlockTime(123), 123, 123},
+		{testBlockTime(499) + 60, 499, 500},
+		{testBlockTime(500), 500, 500},
+		{testBlockTime(800), 801, 800},
+		{testBlockTime(999) + 11, 999, 1000},
+		{testBlockTime(1000), 1000, 1000},
+	}
+	for _, tt := range tests {
+		node.fetches = 0
+		h, err := m.BlockAtTime(ctx, at(tt.ts))
+		if err != nil {
+			t.Fatalf("block at time %d error %s", tt.ts, err)
+		}
+		if h.Number.Uint64() != tt.atOrBefore {
+			t.Errorf("block at time %d is %d, want %d", tt.ts, h.Number, tt.atOrBefore)
+		}
+		h, err = m.FirstBlockAtTime(ctx, at(tt.ts))
+		if err != nil {
+			t.Fatalf("first block at time %d error %s", tt.ts, err)
+		}
+		if h.Number.Uint64() != tt.firstAtOrAfter {
+			t.Errorf("first block at time %d is %d, want %d", tt.ts, h.Number, tt.firstAtOrAfter)
+		}
+		// interpolation falls back to bisection, so the search takes about 2 * log2(1000) headers at most
+		if node.fetches > 2*24 {
+			t.Errorf("block at time %d fetched %d headers", tt.ts, node.fetches)
+		}
+	}
+
+	if h, err := m.BlockAtTime(ctx, at(testBlockTime(1000)+100)); err != nil || h.Number.Uint64() != 1000 {
+		t.Errorf("block at time after head got %v error %v", h, err)
+	}
+	if _, err := m.FirstBlockAtTime(ctx, at(testBlockTime(1000)+1)); err == nil {
+		t.Errorf("first block at time after head should fail")
+	}
+	if _, err := m.BlockAtTime(ctx, at(testGenesisTime-1)); err == nil {
+		t.Errorf("block at time before genesis should fail")
+	}
+	if h, err := m.FirstBlockAtTime(ctx, at(testGenesisTime-1)); err != nil || h.Number.Uint64() != 0 {
+		t.Errorf("first block at time before genesis got %v error %v", h, err)
+	}
+
+	// the headers confirmed below head are cached, only the latest header is fetched again
+	node.fetches = 0
+	if _, err := m.BlockAtTime(ctx, at(testBlockTime(123))); err != nil || node.fetches != 1 {
+		t.Errorf("cached block at time fetched %d headers error %v", node.fetches, err)
+	}
+}
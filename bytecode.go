@@ -0,0 +1,175 @@
+package ethclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	binFileSuffix = ".bin"
+	// libraryPlaceholderLength is the hex length of an unlinked library placeholder, __$<34 hex>$__ or the
+	// legacy __<path:Name>__ padded with underscores, which is replaced by the 20 bytes library address
+	libraryPlaceholderLength = 40
+)
+
+// libraryRef is an unlinked library placeholder of bytecode
+type libraryRef struct {
+	Offset      int    // byte offset in the bytecode
+	Placeholder string // e.g. __$<34 hex>$__
+}
+
+// loadBytecode load the hex bytecode from a .bin file or string, without the 0x prefix and white spaces
+func loadBytecode(strBytecode string) (string, error) {
+	if strings.HasSuffix(strBytecode, binFileSuffix) {
+		data, err := os.ReadFile(strBytecode)
+		if err != nil {
+			return "", fmt.Errorf("read bytecode file %s error: %s", strBytecode, err.Error())
+		}
+		strBytecode = string(data)
+	}
+	return TrimHexPrefix(strings.Join(strings.Fields(strBytecode), "")), nil
+}
+
+// parseBytecode decode the hex bytecode from a .bin file or string, unlinked library placeholders are zero filled
+// and returned as references
+func parseBytecode(strBytecode string) (code []byte, refs []libraryRef, err error) {
+	var strCode string
+	strCode, err = loadBytecode(strBytecode)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sb strings.Builder
+	for i := 0; i < len(strCode); {
+		if strCode[i] != '_' {
+			sb.WriteByte(strCode[i])
+			i++
+			continue
+		}
+		if i%2 != 0 || i+libraryPlaceholderLength > len(strCode) || !strings.HasPrefix(strCode[i:], "__") ||
+			!strings.HasSuffix(strCode[i:i+libraryPlaceholderLength], "__") {
+			return nil, nil, fmt.Errorf("invalid library placeholder at %d of bytecode", i)
+		}
+		refs = append(refs, libraryRef{Offset: i / 2, Placeholder: strCode[i : i+libraryPlaceholderLength]})
+		sb.WriteString(strings.Repeat("0", libraryPlaceholderLength))
+		i += libraryPlaceholderLength
+	}
+	code, err = hexutil.Decode("0x" + sb.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode bytecode error [%s]", err)
+	}
+	return code, refs, nil
+}
+
+// matchBytecode reports whether data equals code, the library addresses at refs match any bytes
+func matchBytecode(code []byte, refs []libraryRef, data []byte) bool {
+	if len(data) != len(code) {
+		return false
+	}
+	start := 0
+	for _, ref := range refs {
+		if !bytes.Equal(code[start:ref.Offset], data[start:ref.Offset]) {
+			return false
+		}
+		start = ref.Offset + libraryPlaceholderLength/2
+	}
+	return bytes.Equal(code[start:], data[start:])
+}
+
+// stripMetadata returns the length of code without the trailing solc CBOR metadata, whose length is encoded
+// in the last 2 bytes, the metadata hash differs between builds of the same source
+func stripMetadata(code []byte) int {
+	if len(code) < 2 {
+		return len(code)
+	}
+	n := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	if n+2 > len(code) || n == 0 || code[len(code)-n-2]&0xe0 != 0xa0 {
+		// not a CBOR map
+		return len(code)
+	}
+	return len(code) - n - 2
+}
+
+// LinkBytecode replace the library placeholders of bytecode (hex string or .bin file) by the library addresses.
+// The libraries are keyed by fully qualified name (path/to/Lib.sol:Lib), by the 34 hex placeholder hash of the
+// __$<hash>$__ format, or by the name of the legacy __<path:Name>__ format
+func LinkBytecode(strBytecode string, libraries map[string]string) (string, error) {
+	strCode, err := loadBytecode(strBytecode)
+	if err != nil {
+		return "", err
+	}
+	placeholders := make(map[string]string)
+	for name, addr := range libraries {
+		if !common.IsHexAddress(addr) {
+			return "", fmt.Errorf("library [%s] address [%s] invalid", name, addr)
+		}
+		strAddr := strings.ToLower(TrimHexPrefix(common.HexToAddress(addr).Hex()))
+		hash := name
+		if !isPlaceholderHash(name) {
+			hash = libraryPlaceholderHash(name)
+		}
+		placeholders["__$"+hash+"$__"] = strAddr
+		if len(name) <= libraryPlaceholderLength-4 {
+			legacy := "__" + name + strings.Repeat("_", libraryPlaceholderLength-4-len(name)) + "__"
+			placeholders[legacy] = strAddr
+		}
+	}
+	var sb strings.Builder
+	for i := 0; i < len(strCode); {
+		if strCode[i] != '_' || i+libraryPlaceholderLength > len(strCode) {
+			sb.WriteByte(strCode[i])
+			i++
+			continue
+		}
+		placeholder := strCode[i : i+libraryPlaceholderLength]
+		addr, ok := placeholders[placeholder]
+		if !ok {
+			var legacy string
+			if legacy, err = legacyPlaceholderName(placeholder); err != nil {
+				return "", fmt.Errorf("%s at %d", err, i)
+			}
+			addr, ok = placeholders[legacy]
+		}
+		if ok {
+			sb.WriteString(addr)
+		} else {
+			sb.WriteString(placeholder)
+		}
+		i += libraryPlaceholderLength
+	}
+	return sb.String(), nil
+}
+
+// libraryPlaceholderHash returns the hash of __$<hash>$__ placeholder, the first 17 bytes of keccak256(fully qualified name)
+func libraryPlaceholderHash(fqName string) string {
+	return common.Bytes2Hex(crypto.Keccak256([]byte(fqName))[:17])
+}
+
+func isPlaceholderHash(s string) bool {
+	if len(s) != 34 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// legacyPlaceholderName returns the placeholder of the library name without path of legacy placeholder __<path:Name>__
+func legacyPlaceholderName(placeholder string) (string, error) {
+	name := strings.Trim(placeholder, "_")
+	if strings.HasPrefix(name, "$") {
+		return placeholder, nil
+	}
+	if !strings.HasPrefix(placeholder, "__") || !strings.HasSuffix(placeholder, "__") || len(name) > libraryPlaceholderLength-4 {
+		return "", fmt.Errorf("library placeholder %q malformed", placeholder)
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	return "__" + name + strings.Repeat("_", libraryPlaceholderLength-4-len(name)) + "__", nil
+}
@@ -0,0 +1,116 @@
+package ethclient
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testMetadata returns the solc CBOR metadata {"ipfs": <34 bytes>, "solc": 0.8.17} of ipfs hash filled by b,
+// followed by its 2 bytes length
+func testMetadata(b byte) string {
+	return "a264697066735822" + strings.Repeat(common.Bytes2Hex([]byte{b}), 34) + "64736f6c6343000811" + "0033"
+}
+
+const (
+	testLibraryHash = "9f4c2a9b1a4f0b4ad6bbd4b1a1c3b2d7e1" // 34 hex placeholder hash
+	// testBytecode calls a library of placeholder testLibraryHash
+	testBytecode = "608060405273__$" + testLibraryHash + "$__5050"
+)
+
+func TestStripMetadata(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{"6080604052" + testMetadata(1), 5},
+		{"6080604052", 5},
+		{"", 0},
+		{"00", 1},
+		// the length points out of code
+		{"60800033", 4},
+		// the length points to a byte which is not a CBOR map
+		{"6080604052" + strings.Replace(testMetadata(1), "a2", "60", 1), 58},
+		{"6080604052" + "0000", 7},
+	}
+	for _, tt := range tests {
+		if n := stripMetadata(common.FromHex(tt.code)); n != tt.want {
+			t.Errorf("strip metadata of %s got %d, want %d", tt.code, n, tt.want)
+		}
+	}
+}
+
+func TestParseBytecode(t *testing.T) {
+	code, refs, err := parseBytecode("0x" + testBytecode + "\n")
+	if err != nil {
+		t.Fatalf("parse bytecode error %s", err)
+	}
+	if len(code) != 5+1+20+2 || len(refs) != 1 || refs[0].Offset != 6 || refs[0].Placeholder != "__$"+testLibraryHash+"$__" {
+		t.Fatalf("parsed code %x refs %v", code, refs)
+	}
+	if !bytes.Equal(code[6:26], make([]byte, 20)) {
+		t.Errorf("placeholder is not zero filled: %x", code)
+	}
+	// the library address at placeholder matches any address
+	linked := common.CopyBytes(code)
+	copy(linked[6:], common.HexToAddress("0x1234").Bytes())
+	if !matchBytecode(code, refs, linked) {
+		t.Errorf("linked bytecode mismatch")
+	}
+	linked[len(linked)-1] = 0x51
+	if matchBytecode(code, refs, linked) || matchBytecode(code, refs, linked[:len(linked)-1]) {
+		t.Errorf("other bytecode matched")
+	}
+	for _, s := range []string{"60806_", "6080__$abc$__", "6080zz", "608"} {
+		if _, _, err = parseBytecode(s); err == nil {
+			t.Errorf("parse bytecode %s should fail", s)
+		}
+	}
+}
+
+func TestLinkBytecode(t *testing.T) {
+	const fqName = "contracts/Lib.sol:Lib"
+	hash := libraryPlaceholderHash(fqName)
+	placeholder := "__$" + hash + "$__"
+	legacy := "__" + fqName + strings.Repeat("_", 40-4-len(fqName)) + "__"
+	lib, other := "0x00000000000000000000000000000000000000Aa", "0x00000000000000000000000000000000000000bb"
+	linkedLib := "00000000000000000000000000000000000000aa"
+	tests := []struct {
+		name      string
+		bytecode  string
+		libraries map[string]string
+		want      string
+	}{
+		{"fully qualified name", "0x6080" + placeholder + "00", map[string]string{fqName: lib}, "6080" + linkedLib + "00"},
+		{"placeholder hash", "6080" + placeholder + "00", map[string]string{hash: lib}, "6080" + linkedLib + "00"},
+		{"twice", "6080" + placeholder + "00" + placeholder, map[string]string{fqName: lib}, "6080" + linkedLib + "00" + linkedLib},
+		{"legacy fully qualified name", "6080" + legacy + "00", map[string]string{fqName: lib}, "6080" + linkedLib + "00"},
+		{"legacy name", "6080" + legacy + "00", map[string]string{"Lib": lib}, "6080" + linkedLib + "00"},
+		{"other library", "6080" + placeholder + "00", map[string]string{"contracts/Other.sol:Other": other}, "6080" + placeholder + "00"},
+		{"no library", "6080" + placeholder + "00", nil, "6080" + placeholder + "00"},
+	}
+	for _, tt := range tests {
+		linked, err := LinkBytecode(tt.bytecode, tt.libraries)
+		if err != nil {
+			t.Fatalf("%s: link bytecode error %s", tt.name, err)
+		}
+		if linked != tt.want {
+			t.Errorf("%s: linked bytecode %s, want %s", tt.name, linked, tt.want)
+		}
+	}
+	if hash != common.Bytes2Hex(crypto.Keccak256([]byte(fqName))[:17]) || len(hash) != 34 {
+		t.Errorf("placeholder hash of %s is %s", fqName, hash)
+	}
+	if _, err := LinkBytecode("6080"+placeholder, map[string]string{fqName: "0x1234"}); err == nil {
+		t.Errorf("link library of invalid address should fail")
+	}
+	// placeholders of a name too long or not closed by __ are malformed
+	for _, malformed := range []string{"__" + strings.Repeat("a", 38), "_" + strings.Repeat("a", 39), "__" + strings.Repeat("a", 37) + "_"} {
+		if _, err := LinkBytecode("6080"+malformed+"00", map[string]string{"Lib": lib}); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("link bytecode of placeholder %s should fail, got error %v", malformed, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Cache is the backend of the response cache, only immutable responses are put into it so entries never expire
+type Cache interface {
+	Get(key string) (value []byte, ok bool)
+	Put(key string, value []byte) error
+}
+
+// memoryCache is a Cache of a fixed number of entries evicted by LRU
+type memoryCache struct {
+	lru *lru.Cache[string, []byte]
+}
+
+// NewMemoryCache new an in-memory LRU cache of size entries
+func NewMemoryCache(size int) Cache {
+	return &memoryCache{lru: lru.NewCache[string, []byte](size)}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	value, ok := c.lru.Get(key)
+	return common.CopyBytes(value), ok
+}
+
+func (c *memoryCache) Put(key string, value []byte) error {
+	c.lru.Add(key, common.CopyBytes(value))
+	return nil
+}
+
+// diskCache is a Cache storing each entry in a file of dir named by the key hash
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache new an on-disk cache in dir, the directory is created if not exists
+func NewDiskCache(dir string) (Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create cache dir [%s] error [%s]", dir, err)
+	}
+	return &diskCache{dir: dir}, nil
+}
+
+func (c *diskCache) path(key string) string {
+	return filepath.Join(c.dir, common.Bytes2Hex(crypto.Keccak256([]byte(key))))
+}
+
+func (c *diskCache) Get(key string) ([]byte, bool) {
+	value, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *diskCache) Put(key string, value []byte) error {
+	f, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(value); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// finalityConfirmations is the depth below the latest head at which a block is taken as finalized when the node does
+// not support the finalized tag (e.g. pre-merge or sidechain nodes), deeper than the reorgs seen on such chains
+const finalityConfirmations = 256
+
+// responseCache caches the immutable responses of client in backend
+type responseCache struct {
+	backend   Cache
+	finalized atomic.Uint64 // highest block number known to be finalized
+}
+
+// finalizedAt reports whether block number is finalized by the finalized tag, blocks confirmed by
+// finalityConfirmations are taken as finalized if the node does not support the tag
+func (m *EthereumClient) finalizedAt(ctx context.Context, number uint64) bool {
+	if number <= m.cache.finalized.Load() {
+		return true
+	}
+	var finalized uint64
+	if h, err := m.ethcli.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber))); err == nil {
+		finalized = h.Number.Uint64()
+	} else if head, err := m.ethcli.BlockNumber(ctx); err == nil && head >= finalityConfirmations {
+		finalized = head - finalityConfirmations
+	} else {
+		return false
+	}
+	for {
+		old := m.cache.finalized.Load()
+		if finalized <= old || m.cache.finalized.CompareAndSwap(old, finalized) {
+			break
+		}
+	}
+	return number <= finalized
+}
+
+// cacheGet decode the cached value of key by decode, a value failed to decode is taken as missing
+func (m *EthereumClient) cacheGet(key string, decode func([]byte) error) bool {
+	if m.cache == nil {
+		return false
+	}
+	value, ok := m.cache.backend.Get(key)
+	if !ok {
+		return false
+	}
+	return decode(value) == nil
+}
+
+// cachePut put the encoded value of key into the cache, errors are ignored as caching is best effort
+func (m *EthereumClient) cachePut(key string, encode func() ([]byte, error)) {
+	if m.cache == nil {
+		return
+	}
+	value, err := encode()
+	if err != nil {
+		return
+	}
+	_ = m.cache.backend.Put(key, value)
+}
+
+func (m *EthereumClient) cachedBlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	key := "block:" + hash.Hex()
+	block := new(types.Block)
+	if m.cacheGet(key, func(b []byte) error { return rlp.DecodeBytes(b, block) }) {
+		return block, nil
+	}
+	block, err := m.ethcli.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	m.cachePut(key, func() ([]byte, error) { return rlp.EncodeToBytes(block) })
+	return block, nil
+}
+
+func (m *EthereumClient) cachedHeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	key := "header:" + hash.Hex()
+	header := new(types.Header)
+	if m.cacheGet(key, func(b []byte) error { return rlp.DecodeBytes(b, header) }) {
+		return header, nil
+	}
+	header, err := m.ethcli.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	m.cachePut(key, func() ([]byte, error) { return rlp.EncodeToBytes(header) })
+	return header, nil
+}
+
+// cachedTransactionReceipt returns the receipt of tx, receipts in finalized blocks are cached
+func (m *EthereumClient) cachedTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	key := "receipt:" + hash.Hex()
+	receipt := new(types.Receipt)
+	if m.cacheGet(key, func(b []byte) error { return json.Unmarshal(b, receipt) }) {
+		return receipt, nil
+	}
+	receipt, err := m.ethcli.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if m.cache != nil && receipt.BlockNumber != nil && m.finalizedAt(ctx, receipt.BlockNumber.Uint64()) {
+		m.cachePut(key, func() ([]byte, error) { return json.Marshal(receipt) })
+	}
+	return receipt, nil
+}
+
+// cachedCodeAt returns the code of address at block number, code at finalized blocks is cached
+func (m *EthereumClient) cachedCodeAt(ctx context.Context, address common.Address, number uint64) ([]byte, error) {
+	key := fmt.Sprintf("code:%s:%d", address.Hex(), number)
+	var code []byte
+	if m.cacheGet(key, func(b []byte) error { code = b; return nil }) {
+		return code, nil
+	}
+	code, err := m.ethcli.CodeAt(ctx, address, Uint642Big(number))
+	if err != nil {
+		return nil, err
+	}
+	if m.cache != nil && m.finalizedAt(ctx, number) {
+		m.cachePut(key, func() ([]byte, error) { return code, nil })
+	}
+	return code, nil
+}
+
+// cachedCallContract returns the result of eth_call at block number, calls at finalized blocks are cached
+func (m *EthereumClient) cachedCallContract(ctx context.Context, msg ethereum.CallMsg, number uint64) ([]byte, error) {
+	key := fmt.Sprintf("call:%d:%s", number, callMsgHash(msg).Hex())
+	var output []byte
+	if m.cacheGet(key, func(b []byte) error { output = b; return nil }) {
+		return output, nil
+	}
+	output, err := m.ethcli.CallContract(ctx, msg, Uint642Big(number))
+	if err != nil {
+		return nil, err
+	}
+	if m.cache != nil && m.finalizedAt(ctx, number) {
+		m.cachePut(key, func() ([]byte, error) { return output, nil })
+	}
+	return output, nil
+}
+
+// cachedCallContractAtHash returns the result of eth_call at block hash, all of them are cached
+func (m *EthereumClient) cachedCallContractAtHash(ctx context.Context, msg ethereum.CallMsg, hash common.Hash) ([]byte, error) {
+	key := fmt.Sprintf("call:%s:%s", hash.Hex(), callMsgHash(msg).Hex())
+	var output []byte
+	if m.cacheGet(key, func(b []byte) error { output = b; return nil }) {
+		return output, nil
+	}
+	output, err := m.ethcli.CallContractAtHash(ctx, msg, hash)
+	if err != nil {
+		return nil, err
+	}
+	m.cachePut(key, func() ([]byte, error) { return output, nil })
+	return output, nil
+}
+
+// callMsgHash returns the hash of the call message as sent to the node
+func callMsgHash(msg ethereum.CallMsg) common.Hash {
+	data, _ := json.Marshal(toCallArg(msg))
+	return crypto.Keccak256Hash(data)
+}
@@ -0,0 +1,57 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeFinalityNode serve the latest block number and the finalized header, a node of zero finalized does not
+// support the finalized tag
+type fakeFinalityNode struct {
+	head      uint64
+	finalized uint64
+}
+
+func (f *fakeFinalityNode) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(f.head)
+}
+
+func (f *fakeFinalityNode) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	if number != "finalized" || f.finalized == 0 {
+		return nil, fmt.Errorf("%s block not found", number)
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(f.finalized), Difficulty: new(big.Int)}, nil
+}
+
+func TestFinalizedAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		node      *fakeFinalityNode
+		finalized uint64
+	}{
+		{"finalized tag", &fakeFinalityNode{head: 1000, finalized: 900}, 900},
+		{"confirmations", &fakeFinalityNode{head: 1000}, 1000 - finalityConfirmations},
+	}
+	for _, tt := range tests {
+		m := newTestClient(t, "eth", tt.node)
+		m.cache = &responseCache{backend: NewMemoryCache(16)}
+		if !m.finalizedAt(context.Background(), tt.finalized) {
+			t.Errorf("%s: block %d not finalized", tt.name, tt.finalized)
+		}
+		if m.finalizedAt(context.Background(), tt.finalized+1) {
+			t.Errorf("%s: block %d finalized", tt.name, tt.finalized+1)
+		}
+	}
+
+	// a chain shorter than the confirmations has no finalized block
+	m := newTestClient(t, "eth", &fakeFinalityNode{head: finalityConfirmations - 1})
+	m.cache = &responseCache{backend: NewMemoryCache(16)}
+	if m.finalizedAt(context.Background(), 1) {
+		t.Errorf("block 1 of chain shorter than %d confirmations finalized", finalityConfirmations)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"math/big"
@@ -15,11 +16,15 @@ import (
 type Option struct {
 	NodeUrl string
 	ABI     string
+	Cache   Cache // cache of immutable responses (blocks by hash, finalized receipts, code and calls at finalized blocks), nil to disable
 }
 
 type EthereumClient struct {
-	ABI    abi.ABI
-	ethcli *ethclient.Client
+	ABI         abi.ABI
+	Registry    *ABIRegistry
+	ethcli      *ethclient.Client
+	timeHeaders *lru.Cache[uint64, *types.Header]
+	cache       *responseCache
 }
 
 func NewEthereumClient(opt *Option) *EthereumClient {
@@ -27,17 +32,28 @@ func NewEthereumClient(opt *Option) *EthereumClient {
 	if err != nil {
 		panic(fmt.Sprintf("dial to ethereum node [%s] error [%s]", opt.NodeUrl, err.Error()))
 	}
-	var abiObj abi.ABI
+	m := newEthereumClient(ethcli, opt.Cache)
 	if opt.ABI != "" {
-		abiObj, err = LoadABI(opt.ABI)
+		m.ABI, err = LoadABI(opt.ABI)
 		if err != nil {
 			panic(err.Error())
 		}
+		m.Registry.RegisterABI(m.ABI)
 	}
-	return &EthereumClient{
-		ethcli: ethcli,
-		ABI:    abiObj,
+	return m
+}
+
+// newEthereumClient new a client of ethcli with an empty ABI registry, cache is nil to disable the response cache
+func newEthereumClient(ethcli *ethclient.Client, cache Cache) *EthereumClient {
+	m := &EthereumClient{
+		ethcli:      ethcli,
+		Registry:    NewABIRegistry(),
+		timeHeaders: lru.NewCache[uint64, *types.Header](blockTimeCacheSize),
+	}
+	if cache != nil {
+		m.cache = &responseCache{backend: cache}
 	}
+	return m
 }
 
 func (m *EthereumClient) Client() *ethclient.Client {
@@ -58,7 +74,7 @@ func (m *EthereumClient) BlockNumber(ctx context.Context) (uint64, error) {
 }
 
 func (m *EthereumClient) BlockByHash(ctx context.Context, hash string) (*types.Block, error) {
-	return m.ethcli.BlockByHash(ctx, Hex2Hash(hash))
+	return m.cachedBlockByHash(ctx, Hex2Hash(hash))
 }
 
 func (m *EthereumClient) BlockByNumber(ctx context.Context, number uint64) (*types.Block, error) {
@@ -70,7 +86,7 @@ func (m *EthereumClient) TransactionByHash(ctx context.Context, hash string) (tx
 }
 
 func (m *EthereumClient) TransactionReceipt(ctx context.Context, hash string) (tx *types.Receipt, err error) {
-	return m.ethcli.TransactionReceipt(ctx, Hex2Hash(hash))
+	return m.cachedTransactionReceipt(ctx, Hex2Hash(hash))
 }
 
 func (m *EthereumClient) PeerCount(ctx context.Context) (uint64, error) {
@@ -82,7 +98,7 @@ func (m *EthereumClient) PeerCount(ctx context.Context) (uint64, error) {
 //}
 
 func (m *EthereumClient) HeaderByHash(ctx context.Context, hash string) (*types.Header, error) {
-	return m.ethcli.HeaderByHash(ctx, Hex2Hash(hash))
+	return m.cachedHeaderByHash(ctx, Hex2Hash(hash))
 }
 
 func (m *EthereumClient) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
@@ -134,7 +150,7 @@ func (m *EthereumClient) StorageAt(ctx context.Context, strAddress, strKey strin
 //}
 
 func (m *EthereumClient) CodeAt(ctx context.Context, strAddress string, number uint64) ([]byte, error) {
-	return m.ethcli.CodeAt(ctx, Hex2Address(strAddress), Int642Big(int64(number)))
+	return m.cachedCodeAt(ctx, Hex2Address(strAddress), number)
 }
 
 //func (m *EthereumClient) CodeAtHash(ctx context.Context, strAddress, strBlockHash string) ([]byte, error) {
@@ -178,11 +194,11 @@ func (m *EthereumClient) PendingTransactionCount(ctx context.Context) (uint, err
 }
 
 func (m *EthereumClient) CallContract(ctx context.Context, msg ethereum.CallMsg, number uint64) ([]byte, error) {
-	return m.ethcli.CallContract(ctx, msg, Uint642Big(number))
+	return m.cachedCallContract(ctx, msg, number)
 }
 
 func (m *EthereumClient) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, strBlockHash string) ([]byte, error) {
-	return m.ethcli.CallContractAtHash(ctx, msg, Hex2Hash(strBlockHash))
+	return m.cachedCallContractAtHash(ctx, msg, Hex2Hash(strBlockHash))
 }
 
 func (m *EthereumClient) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
@@ -254,29 +270,42 @@ func (m *EthereumClient) GetContractAddrByTxHash(ctx context.Context, hash strin
 	return contractAddress.String(), nil
 }
 
+// GetTxCallMethod decode the method called by tx with the ABI registered to the contract (or to its implementation
+// if it is a registered proxy), falling back to the ABIs registered without address and then to m.ABI
 func (m *EthereumClient) GetTxCallMethod(ctx context.Context, hash string) (callMethod *CallMethod, err error) {
 	var tx *types.Transaction
 	tx, _, err = m.TransactionByHash(ctx, hash)
 	if err != nil {
 		return nil, fmt.Errorf("get tx by hash [%s] error [%s]\n", hash, err)
 	}
-	if len(m.ABI.Methods) == 0 {
-		return nil, fmt.Errorf("abi method undefined")
+	if tx.To() == nil {
+		return nil, fmt.Errorf("tx [%s] is a contract creation", hash)
 	}
 	data := tx.Data()
-	id := data[0:4]
+	if len(data) < 4 {
+		return nil, fmt.Errorf("tx [%s] calldata too short to have a method id", hash)
+	}
 	var method *abi.Method
-	method, err = m.ABI.MethodById(id)
+	var contractABI abi.ABI
+	method, contractABI, err = m.Registry.MethodById(tx.To().String(), data[:4])
 	if err != nil {
-		return nil, err
+		// m.ABI may be assigned after the client is created
+		var errABI error
+		if method, errABI = m.ABI.MethodById(data[:4]); errABI != nil {
+			return nil, err
+		}
+		contractABI, err = m.ABI, nil
 	}
 	return &CallMethod{
 		Method: method,
-		ABI:    m.ABI,
+		ABI:    contractABI,
 		Data:   data[4:],
 	}, nil
 }
 
+// GetTxEvents decode the events of tx logs with the ABI registered to their emitter (or to its implementation if it is
+// a registered proxy), falling back to the ABIs registered without address and then to m.ABI. Logs of unknown events
+// are skipped
 func (m *EthereumClient) GetTxEvents(ctx context.Context, hash string) (events []*CallEvent, err error) {
 	var tx *types.Transaction
 	var receipt *types.Receipt
@@ -300,23 +329,39 @@ func (m *EthereumClient) GetTxEvents(ctx context.Context, hash string) (events [
 		return nil, fmt.Errorf("get contract address by tx hash [%s] error: not found", hash)
 	}
 
-	if len(m.ABI.Events) == 0 {
-		return nil, fmt.Errorf("abi event undefined")
-	}
 	for _, lo := range receipt.Logs {
-		var evt *abi.Event
-		evt, err = m.ABI.EventByID(lo.Topics[0])
-		if err != nil {
-			continue //event id not found
-		}
-		if evt == nil {
-			continue
+		// the logs are decoded with the ABI of their emitter, the events of unknown ABIs are skipped
+		evt, contractABI, errEvent := m.Registry.EventByLog(lo.Address.String(), lo.Topics)
+		if errEvent != nil {
+			if evt, errEvent = EventByLog(m.ABI, lo.Topics); errEvent != nil {
+				continue
+			}
+			contractABI = m.ABI
 		}
 		events = append(events, &CallEvent{
 			Event: evt,
 			Log:   *lo,
-			ABI:   m.ABI,
+			ABI:   contractABI,
 		})
 	}
 	return events, nil
 }
+
+// callView pack and call a view method of contract at latest block and unpack the outputs
+func (m *EthereumClient) callView(ctx context.Context, contractABI abi.ABI, strContract string, method string, args ...interface{}) (values []interface{}, err error) {
+	var data, output []byte
+	data, err = contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	contract := Hex2Address(strContract)
+	output, err = m.ethcli.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call method [%s] of contract [%s] error [%s]", method, strContract, err)
+	}
+	values, err = contractABI.Unpack(method, output)
+	if err != nil {
+		return nil, fmt.Errorf("unpack method [%s] output of contract [%s] error [%s]", method, strContract, err)
+	}
+	return values, nil
+}
@@ -78,6 +78,22 @@ func TestGetTxEvents(t *testing.T) {
 	}
 }
 
+func TestTraceTransaction(t *testing.T) {
+	frame, err := cli.TraceTransaction(context.Background(), txHash, &CallTracerConfig{WithLog: true})
+	if err != nil {
+		fmt.Printf("trace tx error %s\n", err)
+		return
+	}
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		var name string
+		if f.Method != nil {
+			name = f.Method.Name()
+		}
+		fmt.Printf("%*s%s %s -> %s method [%s] error [%s]\n", depth*2, "", f.Type, f.From, f.To, name, f.Error)
+		return true
+	})
+}
+
 func printJson(title string, v interface{}) {
 	fmt.Printf("------------------------------------- %s -------------------------------------\n", title)
 	data, _ := json.MarshalIndent(v, "", "\t")
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/civet148/ethclient"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// loadABIFile load contract ABI from an ABI or artifact file of any name, or a directory of artifacts
+func loadABIFile(path string) (abi.ABI, error) {
+	if path == "" {
+		return abi.ABI{}, fmt.Errorf("abi file not set, use -abi")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return abi.ABI{}, fmt.Errorf("read abi file [%s] error [%s]", path, err)
+	}
+	return ethclient.LoadABI(path)
+}
+
+// namedValues map the values to argument names, unnamed arguments are keyed by index
+func namedValues(args abi.Arguments, values []interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		name := fmt.Sprint(i)
+		if i < len(args) && args[i].Name != "" {
+			name = args[i].Name
+		}
+		out[name] = v
+	}
+	return out
+}
+
+// packCall pack the method call of contract ABI with string arguments
+func packCall(contractABI abi.ABI, name string, strArgs []string) (*abi.Method, []byte, error) {
+	method, ok := contractABI.Methods[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("method [%s] not found in abi", name)
+	}
+	values := make([]interface{}, len(strArgs))
+	for i, s := range strArgs {
+		values[i] = s
+	}
+	args, err := ethclient.ConvertArgs(method.Inputs, values...)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := contractABI.Pack(name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &method, data, nil
+}
+
+func cmdDecode(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("decode", "<tx hash>")
+	abiFile := fs.String("abi", "", "contract abi, artifact file or directory")
+	args = parseArgs(fs, args, 1, 1)
+	contractABI, err := loadABIFile(*abiFile)
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := cli.TransactionByHash(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"hash": tx.Hash(),
+		"to":   tx.To(),
+	}
+	if data := tx.Data(); len(data) >= 4 {
+		if method, err := contractABI.MethodById(data[:4]); err == nil {
+			values, err := method.Inputs.UnpackValues(data[4:])
+			if err != nil {
+				return nil, fmt.Errorf("unpack method [%s] inputs error [%s]", method.Name, err)
+			}
+			result["method"] = map[string]interface{}{
+				"name":      method.Name,
+				"signature": method.Sig,
+				"args":      namedValues(method.Inputs, values),
+			}
+		}
+	}
+	receipt, err := cli.TransactionReceipt(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	events := make([]interface{}, 0, len(receipt.Logs))
+	for _, lo := range receipt.Logs {
+		if len(lo.Topics) == 0 {
+			continue
+		}
+		event, err := ethclient.EventByLog(contractABI, lo.Topics)
+		if err != nil {
+			continue
+		}
+		values := make(map[string]interface{})
+		if err = event.Inputs.UnpackIntoMap(values, lo.Data); err != nil {
+			return nil, fmt.Errorf("unpack event [%s] data error [%s]", event.Name, err)
+		}
+		var indexed abi.Arguments
+		for _, arg := range event.Inputs {
+			if arg.Indexed {
+				indexed = append(indexed, arg)
+			}
+		}
+		if err = abi.ParseTopicsIntoMap(values, indexed, lo.Topics[1:]); err != nil {
+			return nil, fmt.Errorf("unpack event [%s] topics error [%s]", event.Name, err)
+		}
+		events = append(events, map[string]interface{}{
+			"address":   lo.Address,
+			"logIndex":  lo.Index,
+			"name":      event.Name,
+			"signature": event.Sig,
+			"args":      values,
+		})
+	}
+	result["events"] = events
+	return result, nil
+}
+
+func cmdRawTx(_ context.Context, _ *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("rawtx", "<raw tx hex>")
+	abiFile := fs.String("abi", "", "contract abi, artifact file or directory to decode the calldata")
+	args = parseArgs(fs, args, 1, 1)
+	tx, err := ethclient.DecodeRawTransaction(args[0])
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"type":       tx.Type,
+		"hash":       tx.Hash,
+		"chainId":    tx.ChainID,
+		"nonce":      tx.Nonce,
+		"from":       tx.From,
+		"to":         tx.To,
+		"value":      tx.Value,
+		"gas":        tx.Gas,
+		"data":       hexutil.Bytes(tx.Data),
+		"accessList": tx.AccessList,
+		"maxCost":    tx.Cost(),
+	}
+	if tx.GasPrice != nil {
+		result["gasPrice"] = tx.GasPrice
+	} else {
+		result["maxPriorityFeePerGas"] = tx.GasTipCap
+		result["maxFeePerGas"] = tx.GasFeeCap
+	}
+	if tx.Type == ethclient.BlobTxType {
+		result["maxFeePerBlobGas"] = tx.BlobFeeCap
+		result["blobVersionedHashes"] = tx.BlobHashes
+		if tx.Sidecar != nil {
+			result["blobs"] = len(tx.Sidecar.Blobs)
+		}
+	}
+	if *abiFile != "" {
+		contractABI, err := loadABIFile(*abiFile)
+		if err != nil {
+			return nil, err
+		}
+		method, err := tx.DecodeCalldata(contractABI)
+		if err != nil {
+			return nil, err
+		}
+		result["method"] = map[string]interface{}{
+			"name":      method.Name(),
+			"signature": method.Sig(),
+			"args":      namedValues(method.Method.Inputs, method.InputValues()),
+		}
+	}
+	return result, nil
+}
+
+func cmdCall(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("call", "<contract> <method> [args...]")
+	abiFile := fs.String("abi", "", "contract abi, artifact file or directory")
+	block := fs.String("block", "latest", "block number or tag")
+	from := fs.String("from", "", "caller address")
+	args = parseArgs(fs, args, 2, -1)
+	contractABI, err := loadABIFile(*abiFile)
+	if err != nil {
+		return nil, err
+	}
+	number, err := parseBlock(*block)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(args[0]) {
+		return nil, fmt.Errorf("invalid contract address [%s]", args[0])
+	}
+	method, data, err := packCall(contractABI, args[1], args[2:])
+	if err != nil {
+		return nil, err
+	}
+	contract := common.HexToAddress(args[0])
+	msg := ethereum.CallMsg{From: common.HexToAddress(*from), To: &contract, Data: data}
+	output, err := cli.Client().CallContract(ctx, msg, number)
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Outputs.UnpackValues(output)
+	if err != nil {
+		return nil, fmt.Errorf("unpack method [%s] outputs error [%s]", method.Name, err)
+	}
+	return namedValues(method.Outputs, values), nil
+}
+
+func cmdSend(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("send", "<to> [method args...]")
+	key := fs.String("key", os.Getenv("ETH_PRIVATE_KEY"), "private key hex (default $ETH_PRIVATE_KEY)")
+	keyDir := fs.String("keystore", "", "keystore directory")
+	from := fs.String("from", "", "keystore account address")
+	password := fs.String("password", os.Getenv("ETH_KEYSTORE_PASSWORD"), "keystore account password (default $ETH_KEYSTORE_PASSWORD)")
+	abiFile := fs.String("abi", "", "contract abi, artifact file or directory to pack the method call")
+	value := fs.String("value", "0", "value in wei")
+	strData := fs.String("data", "", "calldata hex when no method is given")
+	accessList := fs.Bool("access-list", false, "attach an access list if it lowers the gas")
+	dryRun := fs.Bool("dry-run", false, "sign but do not send the transaction")
+	wait := fs.Bool("wait", false, "wait for the transaction to be mined (raise -timeout as needed)")
+	args = parseArgs(fs, args, 1, -1)
+
+	if !common.IsHexAddress(args[0]) {
+		return nil, fmt.Errorf("invalid to address [%s]", args[0])
+	}
+	var err error
+	var data []byte
+	if len(args) > 1 {
+		var contractABI abi.ABI
+		if contractABI, err = loadABIFile(*abiFile); err != nil {
+			return nil, err
+		}
+		if _, data, err = packCall(contractABI, args[1], args[2:]); err != nil {
+			return nil, err
+		}
+	} else if *strData != "" {
+		if data, err = hexutil.Decode(*strData); err != nil {
+			return nil, fmt.Errorf("invalid data [%s]", *strData)
+		}
+	}
+
+	chainId, err := cli.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var opts *bind.TransactOpts
+	switch {
+	case *keyDir != "":
+		ks := ethclient.NewStandardKeyStore(*keyDir)
+		if err = ks.Unlock(*from, *password); err != nil {
+			return nil, err
+		}
+		opts, err = ks.NewTransactOptsWithValue(*from, chainId, *value)
+	case *key != "":
+		opts, err = ethclient.NewTransactOptsWithValue(*key, chainId, *value)
+	default:
+		return nil, fmt.Errorf("no signing key, use -key or -keystore")
+	}
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	opts.NoSend = *dryRun
+
+	tx, err := cli.Transact(ctx, opts, args[0], data, &ethclient.TxOption{AccessList: *accessList})
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"hash":        tx.Hash(),
+		"transaction": tx,
+	}
+	switch {
+	case *dryRun:
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		result["raw"] = hexutil.Bytes(raw)
+	case *wait:
+		receipt, err := bind.WaitMined(ctx, cli.Client(), tx)
+		if err != nil {
+			return nil, fmt.Errorf("wait tx [%s] mined error [%s]", tx.Hash(), err)
+		}
+		result["receipt"] = receipt
+	}
+	return result, nil
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/civet148/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const usage = `usage: ethclient [global options] <command> [options] [args]
+
+global options:
+  -rpc <url>          node url (default $ETH_RPC_URL)
+  -format json|table  output format (default json)
+  -timeout <duration> request timeout (default 30s)
+
+commands:
+  block   <number|hash|latest>
+  tx      <hash>
+  receipt <hash>
+  balance [-block n] <address>
+  nonce   [-block n] <address>
+  code    [-block n] <address>
+  decode  -abi <file> <tx hash>
+  call    -abi <file> [-block n] [-from address] <contract> <method> [args...]
+  rawtx   [-abi <file>] <raw tx hex>                (offline)
+  send    (-key <hex> | -keystore <dir> -from <address> -password <pass>) [-abi <file>] [-value wei]
+          [-data hex] [-access-list] [-dry-run] [-wait] <to> [method args...]
+
+run 'ethclient <command> -h' for the options of a command
+`
+
+type command struct {
+	name    string
+	run     func(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error)
+	offline bool // the command does not need a node
+}
+
+var commands = []command{
+	{"block", cmdBlock, false},
+	{"tx", cmdTx, false},
+	{"receipt", cmdReceipt, false},
+	{"balance", cmdBalance, false},
+	{"nonce", cmdNonce, false},
+	{"code", cmdCode, false},
+	{"decode", cmdDecode, false},
+	{"call", cmdCall, false},
+	{"rawtx", cmdRawTx, true},
+	{"send", cmdSend, false},
+}
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	nodeUrl := flag.String("rpc", os.Getenv("ETH_RPC_URL"), "node url")
+	format := flag.String("format", "json", "output format json or table")
+	timeout := flag.Duration("timeout", 30*time.Second, "request timeout")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != "json" && *format != "table" {
+		fatalf("unknown output format [%s]", *format)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command [%s]\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+	var cli *ethclient.EthereumClient
+	if !cmd.offline {
+		if *nodeUrl == "" {
+			fatalf("node url not set, use -rpc or $ETH_RPC_URL")
+		}
+		cli = ethclient.NewEthereumClient(&ethclient.Option{NodeUrl: *nodeUrl})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	result, err := cmd.run(ctx, cli, flag.Args()[1:])
+	if err != nil {
+		fatalf("%s error [%s]", cmd.name, err)
+	}
+	if err = output(*format, result); err != nil {
+		fatalf("output error [%s]", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// newFlagSet new a flag set of command exiting on error
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: ethclient %s [options] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parse the options of command and check the count of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) []string {
+	_ = fs.Parse(args)
+	if fs.NArg() < min || max >= 0 && fs.NArg() > max {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Args()
+}
+
+// parseBlock parse block number, latest, pending, safe or finalized (empty for latest)
+func parseBlock(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if n, err := strconv.ParseUint(s, 10, 63); err == nil {
+		return new(big.Int).SetUint64(n), nil
+	}
+	var number rpc.BlockNumber
+	if err := number.UnmarshalJSON([]byte(`"` + s + `"`)); err != nil {
+		return nil, fmt.Errorf("invalid block [%s]", s)
+	}
+	if number == rpc.LatestBlockNumber {
+		return nil, nil
+	}
+	return big.NewInt(number.Int64()), nil
+}
+
+// output print result as indented JSON or a table of fields
+func output(format string, result interface{}) error {
+	value := formatValue(reflect.ValueOf(result))
+	if format == "json" {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	printTable(w, "", value)
+	return w.Flush()
+}
+
+func printTable(w *tabwriter.Writer, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			printTable(w, joinKey(prefix, k), v[k])
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s\t[]\n", prefix)
+		}
+		for i, item := range v {
+			printTable(w, joinKey(prefix, fmt.Sprint(i)), item)
+		}
+	case nil:
+		fmt.Fprintf(w, "%s\t\n", prefix)
+	default:
+		fmt.Fprintf(w, "%s\t%v\n", prefix, v)
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// formatValue convert value into JSON friendly maps, slices and scalars, bytes are hex encoded and big integers
+// are decimal strings
+func formatValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case *big.Int:
+		if x == nil {
+			return nil
+		}
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case json.Marshaler:
+		if v.Kind() != reflect.Pointer || !v.IsNil() {
+			var out interface{}
+			if data, err := x.MarshalJSON(); err == nil && json.Unmarshal(data, &out) == nil {
+				return out
+			}
+		}
+	case encoding.TextMarshaler:
+		// e.g. hexutil.Bytes and hexutil.Big
+		if v.Kind() != reflect.Pointer || !v.IsNil() {
+			if data, err := x.MarshalText(); err == nil {
+				return string(data)
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return formatValue(v.Elem())
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return hexutil.Encode(v.Bytes())
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = formatValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			out[fmt.Sprint(k.Interface())] = formatValue(v.MapIndex(k))
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+				name = tag
+			}
+			out[name] = formatValue(v.Field(i))
+		}
+		return out
+	}
+	return v.Interface()
+}
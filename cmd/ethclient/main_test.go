@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// captureOutput returns what fn prints to stdout
+func captureOutput(t *testing.T, fn func() error) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe error %s", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = fn()
+	os.Stdout = stdout
+	_ = w.Close()
+	if err != nil {
+		t.Fatalf("output error %s", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read output error %s", err)
+	}
+	return string(data)
+}
+
+func TestOutputJSONBytes(t *testing.T) {
+	type rawBytes []byte
+	result := map[string]interface{}{
+		"code":  hexutil.Bytes{1, 2, 3},
+		"data":  []byte{0xa9, 0x05},
+		"raw":   rawBytes{0xff},
+		"value": (*hexutil.Big)(big.NewInt(16)),
+		"empty": (*hexutil.Big)(nil),
+	}
+	got := captureOutput(t, func() error { return output("json", result) })
+	want := `{
+  "code": "0x010203",
+  "data": "0xa905",
+  "empty": null,
+  "raw": "0xff",
+  "value": "0x10"
+}
+`
+	if got != want {
+		t.Fatalf("json output got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/civet148/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func cmdBlock(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("block", "<number|hash|latest>")
+	full := fs.Bool("full", false, "output full transactions instead of hashes")
+	args = parseArgs(fs, args, 1, 1)
+
+	var err error
+	var block *types.Block
+	if strings.HasPrefix(args[0], "0x") && len(args[0]) == 66 {
+		block, err = cli.BlockByHash(ctx, args[0])
+	} else {
+		var number *big.Int
+		if number, err = parseBlock(args[0]); err != nil {
+			return nil, err
+		}
+		block, err = cli.Client().BlockByNumber(ctx, number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var txs interface{}
+	if *full {
+		txs = block.Transactions()
+	} else {
+		hashes := make([]common.Hash, 0, block.Transactions().Len())
+		for _, tx := range block.Transactions() {
+			hashes = append(hashes, tx.Hash())
+		}
+		txs = hashes
+	}
+	header := block.Header()
+	return map[string]interface{}{
+		"number":        header.Number,
+		"hash":          block.Hash(),
+		"parentHash":    header.ParentHash,
+		"timestamp":     header.Time,
+		"miner":         header.Coinbase,
+		"gasLimit":      header.GasLimit,
+		"gasUsed":       header.GasUsed,
+		"baseFeePerGas": header.BaseFee,
+		"stateRoot":     header.Root,
+		"size":          block.Size(),
+		"transactions":  txs,
+	}, nil
+}
+
+func cmdTx(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("tx", "<hash>")
+	args = parseArgs(fs, args, 1, 1)
+
+	tx, pending, err := cli.TransactionByHash(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	from, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender error [%s]", err)
+	}
+	return map[string]interface{}{
+		"transaction": tx,
+		"from":        from,
+		"pending":     pending,
+	}, nil
+}
+
+func cmdReceipt(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	fs := newFlagSet("receipt", "<hash>")
+	args = parseArgs(fs, args, 1, 1)
+	return cli.TransactionReceipt(ctx, args[0])
+}
+
+// accountFlags parse the options and address of the account commands
+func accountFlags(name string, args []string) (address common.Address, number *big.Int, err error) {
+	fs := newFlagSet(name, "<address>")
+	block := fs.String("block", "latest", "block number or tag")
+	args = parseArgs(fs, args, 1, 1)
+	if !common.IsHexAddress(args[0]) {
+		return address, nil, fmt.Errorf("invalid address [%s]", args[0])
+	}
+	number, err = parseBlock(*block)
+	return common.HexToAddress(args[0]), number, err
+}
+
+func cmdBalance(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	address, number, err := accountFlags("balance", args)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := cli.Client().BalanceAt(ctx, address, number)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"address": address, "balance": balance}, nil
+}
+
+func cmdNonce(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	address, number, err := accountFlags("nonce", args)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := cli.Client().NonceAt(ctx, address, number)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"address": address, "nonce": nonce}, nil
+}
+
+func cmdCode(ctx context.Context, cli *ethclient.EthereumClient, args []string) (interface{}, error) {
+	address, number, err := accountFlags("code", args)
+	if err != nil {
+		return nil, err
+	}
+	code, err := cli.Client().CodeAt(ctx, address, number)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"address": address, "size": len(code), "code": hexutil.Bytes(code)}, nil
+}
@@ -0,0 +1,137 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ContractCreation is a contract created by a transaction and its decoded constructor arguments
+type ContractCreation struct {
+	Type     string // CREATE or CREATE2
+	Address  common.Address
+	Creator  common.Address // the transaction sender or the factory contract
+	InitCode []byte
+	Args     []interface{}
+}
+
+// DecodeConstructorArgs strip the creation bytecode (hex string or .bin file) from the init code of a contract creation
+// and decode the constructor arguments appended to it. Unlinked library placeholders of bytecode match any address,
+// and a bytecode differing only in the solc metadata hash is accepted if the arguments decode and re-encode exactly
+func DecodeConstructorArgs(contractABI abi.ABI, strBytecode string, initCode []byte) ([]interface{}, error) {
+	code, refs, err := parseBytecode(strBytecode)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConstructorArgs(contractABI, code, refs, initCode)
+}
+
+func decodeConstructorArgs(contractABI abi.ABI, code []byte, refs []libraryRef, initCode []byte) ([]interface{}, error) {
+	if len(initCode) < len(code) {
+		return nil, fmt.Errorf("init code length %d is shorter than bytecode length %d", len(initCode), len(code))
+	}
+	exact := matchBytecode(code, refs, initCode[:len(code)])
+	if !exact {
+		n := stripMetadata(code)
+		if n == len(code) || !matchBytecode(code[:n], refs, initCode[:n]) {
+			return nil, fmt.Errorf("init code does not start with the bytecode")
+		}
+	}
+	data := initCode[len(code):]
+	inputs := contractABI.Constructor.Inputs
+	if len(inputs) == 0 {
+		if len(data) != 0 {
+			return nil, fmt.Errorf("constructor has no inputs but %d bytes of arguments found", len(data))
+		}
+		return nil, nil
+	}
+	values, err := inputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("unpack constructor arguments error [%s]", err)
+	}
+	if !exact {
+		// the metadata length may differ too, make sure the arguments boundary is right
+		if packed, err := inputs.Pack(values...); err != nil || !bytes.Equal(packed, data) {
+			return nil, fmt.Errorf("constructor arguments mismatch with bytecode, metadata of bytecode differs")
+		}
+	}
+	return values, nil
+}
+
+// GetConstructorArgs decode the constructor arguments of the contracts with bytecode (hex string or .bin file) created by
+// transaction hash. A contract creation transaction is decoded from its input, contracts created by factories through
+// CREATE or CREATE2 are found by tracing the transaction (debug_traceTransaction must be available)
+func (m *EthereumClient) GetConstructorArgs(ctx context.Context, hash string, contractABI abi.ABI, strBytecode string) (creations []*ContractCreation, err error) {
+	var code []byte
+	var refs []libraryRef
+	if code, refs, err = parseBytecode(strBytecode); err != nil {
+		return nil, err
+	}
+	var tx *types.Transaction
+	tx, _, err = m.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get tx by hash [%s] error [%s]", hash, err)
+	}
+	if tx.To() == nil {
+		var receipt *types.Receipt
+		receipt, err = m.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("get receipt by hash [%s] error [%s]", hash, err)
+		}
+		var from common.Address
+		from, err = types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+		if err != nil {
+			return nil, err
+		}
+		var args []interface{}
+		if args, err = decodeConstructorArgs(contractABI, code, refs, tx.Data()); err != nil {
+			return nil, err
+		}
+		return []*ContractCreation{{
+			Type:     "CREATE",
+			Address:  receipt.ContractAddress,
+			Creator:  from,
+			InitCode: tx.Data(),
+			Args:     args,
+		}}, nil
+	}
+
+	var frame *CallFrame
+	frame, err = m.TraceTransaction(ctx, hash, &CallTracerConfig{})
+	if err != nil {
+		return nil, err
+	}
+	var decodeErr error
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		if f.Error != "" {
+			// the creations of a reverted call are reverted too
+			return false
+		}
+		if f.IsCreate() {
+			args, err := decodeConstructorArgs(contractABI, code, refs, f.Input)
+			if err != nil {
+				decodeErr = err
+				return true
+			}
+			creations = append(creations, &ContractCreation{
+				Type:     f.Type,
+				Address:  f.To,
+				Creator:  f.From,
+				InitCode: f.Input,
+				Args:     args,
+			})
+		}
+		return true
+	})
+	if len(creations) == 0 {
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		return nil, fmt.Errorf("no contract created by tx [%s]", hash)
+	}
+	return creations, nil
+}
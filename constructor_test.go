@@ -0,0 +1,67 @@
+package ethclient
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testConstructorABI = `[{"inputs":[{"name":"name","type":"string"},{"name":"supply","type":"uint256"},{"name":"owner","type":"address"}],"stateMutability":"nonpayable","type":"constructor"}]`
+
+func TestDecodeConstructorArgs(t *testing.T) {
+	contractABI := mustLoadABI(testConstructorABI)
+	owner := common.HexToAddress(testAddress)
+	args, err := contractABI.Pack("", "Token", big.NewInt(1000), owner)
+	if err != nil {
+		t.Fatalf("pack constructor args error %s", err)
+	}
+	library := strings.ToLower(TrimHexPrefix(common.HexToAddress("0x1234").Hex()))
+	linked := strings.Replace(testBytecode, "__$"+testLibraryHash+"$__", library, 1)
+	tests := []struct {
+		name     string
+		bytecode string
+		initCode string
+	}{
+		{"exact", "0x6080604052" + testMetadata(1), "6080604052" + testMetadata(1)},
+		{"linked library", testBytecode + testMetadata(1), linked + testMetadata(1)},
+		// the same source built elsewhere differs only in the metadata hash
+		{"other metadata", "6080604052" + testMetadata(1), "6080604052" + testMetadata(2)},
+	}
+	for _, tt := range tests {
+		values, err := DecodeConstructorArgs(contractABI, tt.bytecode, common.FromHex(tt.initCode+common.Bytes2Hex(args)))
+		if err != nil {
+			t.Fatalf("%s: decode constructor args error %s", tt.name, err)
+		}
+		if got := fmt.Sprint(values...); got != fmt.Sprint("Token", big.NewInt(1000), owner) {
+			t.Errorf("%s: decoded constructor args %s", tt.name, got)
+		}
+	}
+
+	noArgs := mustLoadABI(`[]`)
+	if values, err := DecodeConstructorArgs(noArgs, "6080604052", common.FromHex("6080604052")); err != nil || values != nil {
+		t.Errorf("decode constructor without inputs got %v error %v", values, err)
+	}
+	errTests := []struct {
+		name        string
+		contractABI string
+		bytecode    string
+		initCode    string
+	}{
+		{"other code", testConstructorABI, "6080604052" + testMetadata(1), "6080604053" + testMetadata(1) + common.Bytes2Hex(args)},
+		{"short init code", testConstructorABI, "6080604052" + testMetadata(1), "6080604052"},
+		{"no metadata", testConstructorABI, "6080604052", "6080604053" + common.Bytes2Hex(args)},
+		// a longer metadata shifts the arguments, which do not re-encode the same
+		{"metadata length", testConstructorABI, "6080604052" + testMetadata(1),
+			"6080604052" + strings.Replace(testMetadata(1), "0033", "010033", 1) + common.Bytes2Hex(args)},
+		{"truncated args", testConstructorABI, "6080604052", "6080604052" + common.Bytes2Hex(args[:64])},
+		{"args of no inputs", `[]`, "6080604052", "6080604052" + common.Bytes2Hex(args)},
+	}
+	for _, tt := range errTests {
+		if _, err = DecodeConstructorArgs(mustLoadABI(tt.contractABI), tt.bytecode, common.FromHex(tt.initCode)); err == nil {
+			t.Errorf("%s: decode constructor args should fail", tt.name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeterministicDeploymentProxy is the canonical CREATE2 deployment proxy deployed at the same address on most chains,
+// it is called with salt ++ init code and returns the created address
+const DeterministicDeploymentProxy = "0x4e59b44847b379578588920cA78FbF26c0B4956C"
+
+// Create2Option is the options of deploying a contract by a CREATE2 factory
+type Create2Option struct {
+	Factory   string                                                  // CREATE2 factory address, default DeterministicDeploymentProxy
+	Pack      func(salt common.Hash, initCode []byte) ([]byte, error) // pack the factory calldata, default salt ++ init code
+	Libraries map[string]string                                       // library addresses to link, see LinkBytecode
+	TxOption  *TxOption
+	NoWait    bool // return the contract address after sending without waiting for the deployment
+}
+
+// CreateAddress returns the address of the contract created by deployer with nonce (CREATE)
+func CreateAddress(strDeployer string, nonce uint64) common.Address {
+	return crypto.CreateAddress(Hex2Address(strDeployer), nonce)
+}
+
+// Create2Address returns the address of the contract created by deployer with salt and init code (CREATE2),
+// keccak256(0xff ++ deployer ++ salt ++ keccak256(init code))[12:]
+func Create2Address(strDeployer string, salt common.Hash, initCode []byte) common.Address {
+	return crypto.CreateAddress2(Hex2Address(strDeployer), salt, crypto.Keccak256(initCode))
+}
+
+// Create2InitCode returns the init code of contract of ABI and creation bytecode (hex string or .bin file) with the
+// constructor arguments for computing its CREATE2 address
+func Create2InitCode(contractABI abi.ABI, strBytecode string, libraries map[string]string, args ...interface{}) ([]byte, error) {
+	strCode, err := LinkBytecode(strBytecode, libraries)
+	if err != nil {
+		return nil, err
+	}
+	return deployInitCode(contractABI, strCode, args)
+}
+
+// DeployCreate2 deploy a contract of ABI and creation bytecode (hex string or .bin file) with salt through a CREATE2
+// factory so it has the same address on every chain with the same factory, salt and init code. No transaction is sent
+// and a nil tx is returned if the contract is already deployed at the address
+func (m *EthereumClient) DeployCreate2(ctx context.Context, opts *bind.TransactOpts, contractABI abi.ABI, strBytecode string, args []interface{}, salt common.Hash, opt *Create2Option) (address common.Address, tx *types.Transaction, err error) {
+	if opt == nil {
+		opt = &Create2Option{}
+	}
+	var initCode []byte
+	if initCode, err = Create2InitCode(contractABI, strBytecode, opt.Libraries, args...); err != nil {
+		return address, nil, err
+	}
+	return m.deployCreate2(ctx, opts, initCode, salt, opt)
+}
+
+// DeployArtifactCreate2 deploy the contract of a Hardhat or Foundry artifact (JSON file or string) with salt through
+// a CREATE2 factory, see DeployCreate2
+func (m *EthereumClient) DeployArtifactCreate2(ctx context.Context, opts *bind.TransactOpts, strArtifact string, args []interface{}, salt common.Hash, opt *Create2Option) (address common.Address, tx *types.Transaction, err error) {
+	if opt == nil {
+		opt = &Create2Option{}
+	}
+	var artifact *ContractArtifact
+	if artifact, err = LoadArtifact(strArtifact); err != nil {
+		return address, nil, err
+	}
+	var strCode string
+	if strCode, err = artifact.LinkBytecode(opt.Libraries); err != nil {
+		return address, nil, err
+	}
+	var initCode []byte
+	if initCode, err = deployInitCode(artifact.ABI, strCode, args); err != nil {
+		return address, nil, err
+	}
+	return m.deployCreate2(ctx, opts, initCode, salt, opt)
+}
+
+func (m *EthereumClient) deployCreate2(ctx context.Context, opts *bind.TransactOpts, initCode []byte, salt common.Hash, opt *Create2Option) (address common.Address, tx *types.Transaction, err error) {
+	factory := opt.Factory
+	if factory == "" {
+		factory = DeterministicDeploymentProxy
+	}
+	address = Create2Address(factory, salt, initCode)
+	var code []byte
+	if code, err = m.ethcli.CodeAt(ctx, address, nil); err != nil {
+		return address, nil, fmt.Errorf("get code of [%s] error [%s]", address, err)
+	}
+	if len(code) != 0 {
+		return address, nil, nil
+	}
+	if code, err = m.ethcli.CodeAt(ctx, Hex2Address(factory), nil); err != nil {
+		return address, nil, fmt.Errorf("get code of [%s] error [%s]", factory, err)
+	}
+	if len(code) == 0 {
+		return address, nil, fmt.Errorf("CREATE2 factory [%s] is not deployed on this chain", factory)
+	}
+	var data []byte
+	if opt.Pack != nil {
+		data, err = opt.Pack(salt, initCode)
+	} else {
+		data = append(salt.Bytes(), initCode...)
+	}
+	if err != nil {
+		return address, nil, err
+	}
+	if tx, err = m.Transact(ctx, opts, factory, data, opt.TxOption); err != nil {
+		return address, nil, err
+	}
+	if opts.NoSend || opt.NoWait {
+		return address, tx, nil
+	}
+	var receipt *types.Receipt
+	if receipt, err = bind.WaitMined(ctx, m.ethcli, tx); err != nil {
+		return address, tx, fmt.Errorf("wait tx [%s] mined error [%s]", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return address, tx, fmt.Errorf("tx [%s] of CREATE2 deployment failed", tx.Hash().Hex())
+	}
+	if code, err = m.ethcli.CodeAt(ctx, address, nil); err != nil {
+		return address, tx, fmt.Errorf("get code of [%s] error [%s]", address, err)
+	}
+	if len(code) == 0 {
+		return address, tx, fmt.Errorf("no code at [%s] after CREATE2 deployment", address)
+	}
+	return address, tx, nil
+}
@@ -0,0 +1,132 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCreateAddress(t *testing.T) {
+	// sender 0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0 of nonce 0 to 3
+	for nonce, want := range []string{
+		"0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d",
+		"0x343c43a37d37dff08ae8c4a11544c718abb4fcf8",
+		"0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91",
+		"0xfffd933a0bc612844eaf0c6fe3e5b8e9b6c1d19c",
+	} {
+		if addr := CreateAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", uint64(nonce)); addr != common.HexToAddress(want) {
+			t.Errorf("create address of nonce %d is %s, want %s", nonce, addr, want)
+		}
+	}
+}
+
+// examples from https://eips.ethereum.org/EIPS/eip-1014
+func TestCreate2Address(t *testing.T) {
+	tests := []struct {
+		deployer string
+		salt     string
+		initCode string
+		want     string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x00", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00",
+			"0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0xdeadbeef", "0x70f2b2914A2a4b783FaEFb75f459A580616Fcb5e"},
+		{"0x00000000000000000000000000000000deadbeef", "0xcafebabe", "0xdeadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+		{"0x00000000000000000000000000000000deadbeef", "0xcafebabe",
+			"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			"0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x", "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"},
+	}
+	for _, tt := range tests {
+		addr := Create2Address(tt.deployer, common.HexToHash(tt.salt), hexutil.MustDecode(tt.initCode))
+		if addr.Hex() != tt.want {
+			t.Errorf("create2 address of deployer %s salt %s init code %s is %s, want %s", tt.deployer, tt.salt, tt.initCode, addr.Hex(), tt.want)
+		}
+	}
+}
+
+func TestCreate2InitCode(t *testing.T) {
+	contractABI := mustLoadABI(testConstructorABI)
+	owner := common.HexToAddress("0xa1")
+	initCode, err := Create2InitCode(contractABI, "0x6080604052", nil, "Token", big.NewInt(1000), owner)
+	if err != nil {
+		t.Fatalf("create2 init code error %s", err)
+	}
+	args, err := contractABI.Pack("", "Token", big.NewInt(1000), owner)
+	if err != nil {
+		t.Fatalf("pack constructor error %s", err)
+	}
+	if !bytes.Equal(initCode, append(common.FromHex("0x6080604052"), args...)) {
+		t.Fatalf("create2 init code %x", initCode)
+	}
+	if _, err = Create2InitCode(contractABI, testBytecode, nil, "Token", big.NewInt(1000), owner); err == nil {
+		t.Fatalf("create2 init code of unlinked library should fail")
+	}
+}
+
+// fakeCreate2Node serve the code of deployed contracts
+type fakeCreate2Node struct {
+	code map[common.Address][]byte
+}
+
+func (f *fakeCreate2Node) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (f *fakeCreate2Node) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return f.code[addr]
+}
+
+func TestDeployCreate2(t *testing.T) {
+	contractABI := mustLoadABI(testConstructorABI)
+	args := []interface{}{"Token", big.NewInt(1000), common.HexToAddress("0xa1")}
+	salt := common.HexToHash("0x01")
+	initCode, err := Create2InitCode(contractABI, "6080604052", nil, args...)
+	if err != nil {
+		t.Fatalf("create2 init code error %s", err)
+	}
+	factory := Hex2Address(DeterministicDeploymentProxy)
+	want := Create2Address(DeterministicDeploymentProxy, salt, initCode)
+	var sent *types.Transaction
+	opts := &bind.TransactOpts{
+		From:     Hex2Address(testAddress),
+		Nonce:    big.NewInt(1),
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 1000000,
+		NoSend:   true,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			sent = tx
+			return tx, nil
+		},
+	}
+	runtime := []byte{0x60, 0x80}
+
+	// already deployed: no transaction is built
+	m := newTestClient(t, "eth", &fakeCreate2Node{code: map[common.Address][]byte{want: runtime, factory: runtime}})
+	address, tx, err := m.DeployCreate2(context.Background(), opts, contractABI, "6080604052", args, salt, nil)
+	if err != nil || address != want || tx != nil || sent != nil {
+		t.Fatalf("deploy create2 of deployed contract got %s tx %v error %v", address, tx, err)
+	}
+
+	m = newTestClient(t, "eth", &fakeCreate2Node{code: map[common.Address][]byte{factory: runtime}})
+	address, tx, err = m.DeployCreate2(context.Background(), opts, contractABI, "6080604052", args, salt, nil)
+	if err != nil || address != want || tx == nil {
+		t.Fatalf("deploy create2 got %s tx %v error %v", address, tx, err)
+	}
+	if *tx.To() != factory || !bytes.Equal(tx.Data(), append(salt.Bytes(), initCode...)) {
+		t.Fatalf("deploy create2 tx to %s data %x", tx.To(), tx.Data())
+	}
+
+	m = newTestClient(t, "eth", &fakeCreate2Node{})
+	if _, _, err = m.DeployCreate2(context.Background(), opts, contractABI, "6080604052", args, salt, nil); err == nil {
+		t.Fatalf("deploy create2 without factory should fail")
+	}
+}
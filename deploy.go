@@ -0,0 +1,88 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeployOption is the options of deploying a contract
+type DeployOption struct {
+	Libraries map[string]string // library addresses to link, see LinkBytecode
+	TxOption  *TxOption
+	NoWait    bool // return the expected contract address after sending without waiting for the deployment
+}
+
+// Deploy deploy a contract of ABI and creation bytecode (hex string or .bin file) with the constructor arguments
+// converted from loosely typed values (see ConvertArg), and wait for the contract deployed
+func (m *EthereumClient) Deploy(ctx context.Context, opts *bind.TransactOpts, contractABI abi.ABI, strBytecode string, args []interface{}, deployOpt *DeployOption) (address common.Address, tx *types.Transaction, err error) {
+	if deployOpt == nil {
+		deployOpt = &DeployOption{}
+	}
+	var strCode string
+	if strCode, err = LinkBytecode(strBytecode, deployOpt.Libraries); err != nil {
+		return address, nil, err
+	}
+	return m.deploy(ctx, opts, contractABI, strCode, args, deployOpt)
+}
+
+// DeployArtifact deploy the contract of a Hardhat or Foundry artifact (JSON file or string), see Deploy
+func (m *EthereumClient) DeployArtifact(ctx context.Context, opts *bind.TransactOpts, strArtifact string, args []interface{}, deployOpt *DeployOption) (address common.Address, tx *types.Transaction, err error) {
+	if deployOpt == nil {
+		deployOpt = &DeployOption{}
+	}
+	var artifact *ContractArtifact
+	if artifact, err = LoadArtifact(strArtifact); err != nil {
+		return address, nil, err
+	}
+	var strCode string
+	if strCode, err = artifact.LinkBytecode(deployOpt.Libraries); err != nil {
+		return address, nil, err
+	}
+	return m.deploy(ctx, opts, artifact.ABI, strCode, args, deployOpt)
+}
+
+func (m *EthereumClient) deploy(ctx context.Context, opts *bind.TransactOpts, contractABI abi.ABI, strCode string, args []interface{}, deployOpt *DeployOption) (address common.Address, tx *types.Transaction, err error) {
+	var data []byte
+	if data, err = deployInitCode(contractABI, strCode, args); err != nil {
+		return address, nil, err
+	}
+	if tx, err = m.Transact(ctx, opts, "", data, deployOpt.TxOption); err != nil {
+		return address, nil, err
+	}
+	if opts.NoSend || deployOpt.NoWait {
+		return crypto.CreateAddress(opts.From, tx.Nonce()), tx, nil
+	}
+	if address, err = bind.WaitDeployed(ctx, m.ethcli, tx); err != nil {
+		return address, tx, fmt.Errorf("wait tx [%s] deployed error [%s]", tx.Hash().Hex(), err)
+	}
+	return address, tx, nil
+}
+
+// deployInitCode returns the linked creation bytecode followed by the packed constructor arguments
+func deployInitCode(contractABI abi.ABI, strCode string, args []interface{}) ([]byte, error) {
+	code, refs, err := parseBytecode(strCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) != 0 {
+		return nil, fmt.Errorf("library placeholder %s at %d not linked", refs[0].Placeholder, refs[0].Offset)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("bytecode is empty, the contract may be abstract or an interface")
+	}
+	values, err := ConvertArgs(contractABI.Constructor.Inputs, args...)
+	if err != nil {
+		return nil, fmt.Errorf("constructor %s", err)
+	}
+	input, err := contractABI.Pack("", values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(code, input...), nil
+}
@@ -0,0 +1,60 @@
+package ethclient
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDeployInitCode(t *testing.T) {
+	contractABI := mustLoadABI(testConstructorABI)
+	owner := common.HexToAddress(testAddress)
+	packed, err := contractABI.Pack("", "Token", big.NewInt(1000), owner)
+	if err != nil {
+		t.Fatalf("pack constructor args error %s", err)
+	}
+	tests := []struct {
+		name string
+		args []interface{}
+	}{
+		{"go types", []interface{}{"Token", big.NewInt(1000), owner}},
+		{"strings", []interface{}{"Token", "1000", testAddress}},
+		{"hex and numbers", []interface{}{"Token", "0x3e8", owner.Hex()}},
+		{"json number", []interface{}{"Token", float64(1000), strings.ToLower(testAddress)}},
+	}
+	for _, tt := range tests {
+		initCode, err := deployInitCode(contractABI, "0x6080604052", tt.args)
+		if err != nil {
+			t.Fatalf("%s: deploy init code error %s", tt.name, err)
+		}
+		if want := "6080604052" + common.Bytes2Hex(packed); common.Bytes2Hex(initCode) != want {
+			t.Errorf("%s: init code %x, want %s", tt.name, initCode, want)
+		}
+	}
+	// the init code decodes back to the arguments
+	initCode, _ := deployInitCode(contractABI, "6080604052", tests[1].args)
+	if values, err := DecodeConstructorArgs(contractABI, "6080604052", initCode); err != nil || values[0] != "Token" {
+		t.Errorf("decode deployed constructor args %v error %v", values, err)
+	}
+	if initCode, err = deployInitCode(mustLoadABI(`[]`), "6080604052", nil); err != nil || common.Bytes2Hex(initCode) != "6080604052" {
+		t.Errorf("init code without constructor %x error %v", initCode, err)
+	}
+
+	errTests := []struct {
+		name     string
+		bytecode string
+		args     []interface{}
+	}{
+		{"unlinked library", testBytecode, []interface{}{"Token", 1000, owner}},
+		{"empty bytecode", "0x", []interface{}{"Token", 1000, owner}},
+		{"missing args", "6080604052", []interface{}{"Token", 1000}},
+		{"invalid arg", "6080604052", []interface{}{"Token", -1, owner}},
+	}
+	for _, tt := range errTests {
+		if _, err = deployInitCode(contractABI, tt.bytecode, tt.args); err == nil {
+			t.Errorf("%s: deploy init code should fail", tt.name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	eip712DomainType = "EIP712Domain"
+)
+
+// NewTypedData build EIP-712 typed data, the EIP712Domain type is derived from the domain fields if not present in types.
+// Message values are JSON-like: addresses and bytes as hex strings, integers as *big.Int or decimal/hex strings
+func NewTypedData(primaryType string, domain apitypes.TypedDataDomain, types apitypes.Types, message map[string]interface{}) *apitypes.TypedData {
+	td := &apitypes.TypedData{
+		Types:       apitypes.Types{},
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+	for name, fields := range types {
+		td.Types[name] = fields
+	}
+	if _, ok := td.Types[eip712DomainType]; !ok {
+		td.Types[eip712DomainType] = typedDataDomainFields(domain)
+	}
+	return td
+}
+
+// ParseTypedData parse EIP-712 typed data from JSON (as used by eth_signTypedData_v4)
+func ParseTypedData(strJSON string) (td *apitypes.TypedData, err error) {
+	td = &apitypes.TypedData{}
+	if err = json.Unmarshal([]byte(strJSON), td); err != nil {
+		return nil, fmt.Errorf("unmarshal typed data json error: %s", err.Error())
+	}
+	if td.PrimaryType == "" {
+		return nil, fmt.Errorf("typed data primary type undefined")
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("typed data primary type [%s] not found in types", td.PrimaryType)
+	}
+	if _, ok := td.Types[eip712DomainType]; !ok {
+		td.Types[eip712DomainType] = typedDataDomainFields(td.Domain)
+	}
+	return td, nil
+}
+
+// TypedDataDomainSeparator returns hashStruct(domain)
+func TypedDataDomainSeparator(td *apitypes.TypedData) (common.Hash, error) {
+	hash, err := td.HashStruct(eip712DomainType, td.Domain.Map())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// TypedDataStructHash returns hashStruct(message) of the primary type
+func TypedDataStructHash(td *apitypes.TypedData) (common.Hash, error) {
+	hash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// TypedDataHash returns keccak256("\x19\x01" || domainSeparator || hashStruct(message)) to be signed
+func TypedDataHash(td *apitypes.TypedData) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(*td)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// SignTypedData sign typed data by private key (hex string, bytes or *ecdsa.PrivateKey) or Signer,
+// the signature V is 27 or 28 as eth_signTypedData_v4 returns
+func SignTypedData(signer interface{}, td *apitypes.TypedData) (sig []byte, err error) {
+	var hash common.Hash
+	hash, err = TypedDataHash(td)
+	if err != nil {
+		return nil, err
+	}
+	return signHash(signer, hash.Bytes())
+}
+
+// RecoverTypedData recover the signer address of typed data signature
+func RecoverTypedData(td *apitypes.TypedData, sig []byte) (addr common.Address, err error) {
+	var hash common.Hash
+	hash, err = TypedDataHash(td)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return RecoverHash(hash.Bytes(), sig)
+}
+
+// VerifyTypedData check the typed data signature was signed by address
+func VerifyTypedData(td *apitypes.TypedData, sig []byte, strAddress string) (bool, error) {
+	addr, err := RecoverTypedData(td, sig)
+	if err != nil {
+		return false, err
+	}
+	return addr == Hex2Address(strAddress), nil
+}
+
+// typedDataDomainFields returns the EIP712Domain type fields in canonical order for the non-empty domain fields
+func typedDataDomainFields(domain apitypes.TypedDataDomain) (fields []apitypes.Type) {
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
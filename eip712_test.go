@@ -0,0 +1,104 @@
+package ethclient
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// example from https://eips.ethereum.org/EIPS/eip-712
+const testTypedDataJSON = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+const (
+	testTypedDataDomainSeparator = "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"
+	testTypedDataStructHash      = "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"
+	testTypedDataHash            = "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"
+	testTypedDataSignature       = "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+)
+
+func TestTypedDataHash(t *testing.T) {
+	td, err := ParseTypedData(testTypedDataJSON)
+	if err != nil {
+		t.Fatalf("parse typed data error %s", err)
+	}
+	domainSeparator, err := TypedDataDomainSeparator(td)
+	if err != nil || domainSeparator.Hex() != testTypedDataDomainSeparator {
+		t.Fatalf("domain separator %s error %v", domainSeparator.Hex(), err)
+	}
+	structHash, err := TypedDataStructHash(td)
+	if err != nil || structHash.Hex() != testTypedDataStructHash {
+		t.Fatalf("struct hash %s error %v", structHash.Hex(), err)
+	}
+	hash, err := TypedDataHash(td)
+	if err != nil || hash.Hex() != testTypedDataHash {
+		t.Fatalf("typed data hash %s error %v", hash.Hex(), err)
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	td := NewTypedData("Mail",
+		apitypes.TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(1),
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		apitypes.Types{
+			"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+			"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+		},
+		map[string]interface{}{
+			"from":     map[string]interface{}{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!",
+		})
+	key := crypto.Keccak256([]byte("cow"))
+	sig, err := SignTypedData(key, td)
+	if err != nil {
+		t.Fatalf("sign typed data error %s", err)
+	}
+	if hexutil.Encode(sig) != testTypedDataSignature {
+		t.Fatalf("typed data signature %s, want %s", hexutil.Encode(sig), testTypedDataSignature)
+	}
+	ok, err := VerifyTypedData(td, sig, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	if err != nil || !ok {
+		t.Fatalf("verify typed data %v error %v", ok, err)
+	}
+	td.Message["contents"] = "Hello, Alice!"
+	if ok, _ = VerifyTypedData(td, sig, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"); ok {
+		t.Fatalf("verify tampered typed data should fail")
+	}
+}
@@ -0,0 +1,303 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	headTrackerDefaultPollInterval       = 12 * time.Second
+	headTrackerDefaultCacheSize          = 1024
+	headTrackerDefaultCheckpointInterval = time.Minute
+)
+
+// HeadTrackerOption is the options of HeadTracker
+type HeadTrackerOption struct {
+	PollInterval time.Duration // interval of polling the latest head when subscription is not available, default 12s
+	CacheSize    int           // number of recent headers cached by number and hash, default 1024
+	Poll         bool          // poll the latest head even if the node supports subscription
+	// interval of refetching the safe and finalized heads (they move once an epoch on Ethereum), default 1m. They
+	// are also refetched on reorg
+	CheckpointInterval time.Duration
+}
+
+// ReorgEvent is a chain reorganization detected by HeadTracker
+type ReorgEvent struct {
+	Depth    uint64        // number of blocks of the old chain replaced
+	Ancestor uint64        // number of the common ancestor block
+	OldHead  *types.Header // head before the reorg
+	NewHead  *types.Header // head after the reorg
+}
+
+// HeadTracker tracks the latest, safe and finalized heads by SubscribeNewHead (or polling), caches recent
+// canonical headers by number and hash and detects reorgs by parent hash mismatch
+type HeadTracker struct {
+	cli            *EthereumClient
+	opt            HeadTrackerOption
+	lock           sync.RWMutex
+	latest         *types.Header
+	safe           *types.Header
+	finalized      *types.Header
+	checkpointAt   time.Time // time of fetching the safe and finalized heads
+	byNumber       *lru.Cache[uint64, common.Hash]
+	byHash         *lru.Cache[common.Hash, *types.Header]
+	headListeners  []func(head *types.Header)
+	reorgListeners []func(event *ReorgEvent)
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewHeadTracker new a head tracker of client, call Start to begin tracking
+func (m *EthereumClient) NewHeadTracker(opt *HeadTrackerOption) *HeadTracker {
+	var o HeadTrackerOption
+	if opt != nil {
+		o = *opt
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = headTrackerDefaultPollInterval
+	}
+	if o.CacheSize <= 0 {
+		o.CacheSize = headTrackerDefaultCacheSize
+	}
+	if o.CheckpointInterval <= 0 {
+		o.CheckpointInterval = headTrackerDefaultCheckpointInterval
+	}
+	return &HeadTracker{
+		cli:      m,
+		opt:      o,
+		byNumber: lru.NewCache[uint64, common.Hash](o.CacheSize),
+		byHash:   lru.NewCache[common.Hash, *types.Header](o.CacheSize),
+	}
+}
+
+// OnNewHead register a listener called with each new head
+func (m *HeadTracker) OnNewHead(fn func(head *types.Header)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.headListeners = append(m.headListeners, fn)
+}
+
+// OnReorg register a listener called when a reorg is detected, before the new head listeners
+func (m *HeadTracker) OnReorg(fn func(event *ReorgEvent)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.reorgListeners = append(m.reorgListeners, fn)
+}
+
+// Start fetch the current heads and track new heads in background until Stop is called or ctx is done
+func (m *HeadTracker) Start(ctx context.Context) error {
+	head, err := m.cli.ethcli.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("get latest header error [%s]", err)
+	}
+	if err = m.handle(ctx, head); err != nil {
+		return err
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	ch := make(chan *types.Header, 16)
+	var sub ethereum.Subscription
+	if !m.opt.Poll {
+		if s, err := m.cli.ethcli.SubscribeNewHead(ctx, ch); err == nil {
+			sub = s
+		}
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if sub != nil {
+			m.loopSubscription(ctx, sub, ch)
+		}
+		m.loopPolling(ctx)
+	}()
+	return nil
+}
+
+// Stop stop tracking new heads
+func (m *HeadTracker) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// Latest returns the latest head
+func (m *HeadTracker) Latest() *types.Header {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.latest
+}
+
+// Safe returns the safe head, nil if the node does not support it
+func (m *HeadTracker) Safe() *types.Header {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.safe
+}
+
+// Finalized returns the finalized head, nil if the node does not support it
+func (m *HeadTracker) Finalized() *types.Header {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.finalized
+}
+
+// HeaderByNumber returns the canonical header of number from cache or the node
+func (m *HeadTracker) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	if hash, ok := m.byNumber.Get(number); ok {
+		if h, ok := m.byHash.Get(hash); ok {
+			return h, nil
+		}
+	}
+	h, err := m.cli.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	m.byHash.Add(h.Hash(), h)
+	return h, nil
+}
+
+// HeaderByHash returns the header of hash from cache or the node
+func (m *HeadTracker) HeaderByHash(ctx context.Context, hash string) (*types.Header, error) {
+	return m.headerByHash(ctx, Hex2Hash(hash))
+}
+
+func (m *HeadTracker) headerByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if h, ok := m.byHash.Get(hash); ok {
+		return h, nil
+	}
+	h, err := m.cli.ethcli.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get header by hash [%s] error [%s]", hash.Hex(), err)
+	}
+	m.byHash.Add(hash, h)
+	return h, nil
+}
+
+func (m *HeadTracker) loopSubscription(ctx context.Context, sub ethereum.Subscription, ch chan *types.Header) {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Err():
+			// fall back to polling when the subscription is broken
+			return
+		case head := <-ch:
+			_ = m.handle(ctx, head)
+		}
+	}
+}
+
+func (m *HeadTracker) loopPolling(ctx context.Context) {
+	ticker := time.NewTicker(m.opt.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			head, err := m.cli.ethcli.HeaderByNumber(ctx, nil)
+			if err != nil {
+				continue
+			}
+			if latest := m.Latest(); latest != nil && latest.Hash() == head.Hash() {
+				continue
+			}
+			_ = m.handle(ctx, head)
+		}
+	}
+}
+
+// handle link the new head to the cached canonical chain, fetching the missing parents by hash, and detect reorg
+func (m *HeadTracker) handle(ctx context.Context, head *types.Header) error {
+	old := m.Latest()
+	if old != nil && old.Hash() == head.Hash() {
+		return nil
+	}
+	chain := []*types.Header{head}
+	for cur := head; cur.Number.Uint64() > 0 && len(chain) < m.opt.CacheSize; {
+		parentNum := cur.Number.Uint64() - 1
+		hash, ok := m.byNumber.Get(parentNum)
+		if ok && hash == cur.ParentHash {
+			break
+		}
+		// unknown history below the old head is assumed canonical
+		if !ok && (old == nil || parentNum <= old.Number.Uint64()) {
+			break
+		}
+		parent, err := m.headerByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, parent)
+		cur = parent
+	}
+	lowest := chain[len(chain)-1].Number.Uint64()
+	for _, h := range chain {
+		m.byHash.Add(h.Hash(), h)
+		m.byNumber.Add(h.Number.Uint64(), h.Hash())
+	}
+
+	var reorg *ReorgEvent
+	if old != nil {
+		oldNum, headNum := old.Number.Uint64(), head.Number.Uint64()
+		for n := headNum + 1; n <= oldNum; n++ {
+			m.byNumber.Remove(n)
+		}
+		if hash, ok := m.byNumber.Get(oldNum); !ok || hash != old.Hash() {
+			ancestor := uint64(0)
+			if lowest > 0 {
+				ancestor = lowest - 1
+			}
+			reorg = &ReorgEvent{
+				Depth:    oldNum - ancestor,
+				Ancestor: ancestor,
+				OldHead:  old,
+				NewHead:  head,
+			}
+		}
+	}
+
+	var safe, finalized *types.Header
+	m.lock.RLock()
+	checkpoint := reorg != nil || time.Since(m.checkpointAt) >= m.opt.CheckpointInterval
+	m.lock.RUnlock()
+	if checkpoint {
+		safe, _ = m.cli.ethcli.HeaderByNumber(ctx, big.NewInt(int64(rpc.SafeBlockNumber)))
+		finalized, _ = m.cli.ethcli.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	}
+
+	m.lock.Lock()
+	m.latest = head
+	if checkpoint {
+		m.checkpointAt = time.Now()
+	}
+	if safe != nil {
+		m.safe = safe
+	}
+	if finalized != nil {
+		m.finalized = finalized
+	}
+	headListeners := append([]func(*types.Header){}, m.headListeners...)
+	reorgListeners := append([]func(*ReorgEvent){}, m.reorgListeners...)
+	m.lock.Unlock()
+
+	if reorg != nil {
+		for _, fn := range reorgListeners {
+			fn(reorg)
+		}
+	}
+	for _, fn := range headListeners {
+		fn(head)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChainNode serve the headers of a chain and its forks by hash, the canonical headers by number and the
+// genesis as the safe and finalized heads
+type fakeChainNode struct {
+	lock        sync.Mutex
+	headers     map[common.Hash]*types.Header
+	canonical   map[uint64]*types.Header
+	head        *types.Header
+	byHash      int // count of headers fetched by hash
+	byNumber    int // count of headers fetched by number
+	checkpoints int // count of safe and finalized heads fetched
+}
+
+func newFakeChainNode() *fakeChainNode {
+	genesis := &types.Header{Number: new(big.Int), Difficulty: new(big.Int)}
+	return &fakeChainNode{
+		headers:   map[common.Hash]*types.Header{genesis.Hash(): genesis},
+		canonical: map[uint64]*types.Header{0: genesis},
+		head:      genesis,
+	}
+}
+
+// extend add n headers of fork on top of parent and make them canonical
+func (f *fakeChainNode) extend(parent *types.Header, n int, fork byte) []*types.Header {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var chain []*types.Header
+	for i := 0; i < n; i++ {
+		h := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			Difficulty: new(big.Int),
+			Extra:      []byte{fork},
+		}
+		f.headers[h.Hash()] = h
+		f.canonical[h.Number.Uint64()] = h
+		chain = append(chain, h)
+		parent = h
+	}
+	for n := parent.Number.Uint64() + 1; f.canonical[n] != nil; n++ {
+		delete(f.canonical, n)
+	}
+	f.head = parent
+	return chain
+}
+
+func (f *fakeChainNode) genesis() *types.Header {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.canonical[0]
+}
+
+func (f *fakeChainNode) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch number {
+	case "latest":
+		return f.head, nil
+	case "safe", "finalized":
+		f.checkpoints++
+		return f.canonical[0], nil
+	}
+	n, err := hexutil.DecodeUint64(number)
+	if err != nil {
+		return nil, err
+	}
+	f.byNumber++
+	return f.canonical[n], nil
+}
+
+func (f *fakeChainNode) GetBlockByHash(hash common.Hash, full bool) *types.Header {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.byHash++
+	return f.headers[hash]
+}
+
+// newTestHeadTracker returns a head tracker of node recording the reorgs
+func newTestHeadTracker(t *testing.T, node *fakeChainNode, opt *HeadTrackerOption) (*HeadTracker, *[]*ReorgEvent) {
+	tracker := newTestClient(t, "eth", node).NewHeadTracker(opt)
+	var reorgs []*ReorgEvent
+	tracker.OnReorg(func(event *ReorgEvent) { reorgs = append(reorgs, event) })
+	return tracker, &reorgs
+}
+
+func TestHeadTrackerExtend(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeChainNode()
+	tracker, reorgs := newTestHeadTracker(t, node, nil)
+	var heads []*types.Header
+	tracker.OnNewHead(func(head *types.Header) { heads = append(heads, head) })
+	chain := node.extend(node.genesis(), 5, 'a')
+	for _, h := range chain {
+		if err := tracker.handle(ctx, h); err != nil {
+			t.Fatalf("handle head %d error %s", h.Number, err)
+		}
+	}
+	// the same head again is ignored
+	if err := tracker.handle(ctx, chain[4]); err != nil {
+		t.Fatalf("handle head again error %s", err)
+	}
+	if len(*reorgs) != 0 || len(heads) != 5 || tracker.Latest() != chain[4] {
+		t.Fatalf("linear chain reported %d reorgs %d heads, latest %v", len(*reorgs), len(heads), tracker.Latest().Number)
+	}
+	for _, h := range chain {
+		got, err := tracker.HeaderByNumber(ctx, h.Number.Uint64())
+		if err != nil || got.Hash() != h.Hash() {
+			t.Fatalf("header %d of tracker got %v error %v", h.Number, got, err)
+		}
+	}
+	if node.byNumber != 0 || node.byHash != 0 {
+		t.Fatalf("linear chain fetched %d headers by number %d by hash, want cached", node.byNumber, node.byHash)
+	}
+	// the checkpoints are fetched once in the interval
+	if node.checkpoints != 2 || tracker.Finalized() == nil || tracker.Safe() == nil {
+		t.Fatalf("safe and finalized heads fetched %d times, want 2", node.checkpoints)
+	}
+}
+
+func TestHeadTrackerReorg(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		old      int // length of old chain a
+		ancestor int // fork b from block ancestor of a
+		fork     int // length of new chain b
+		depth    uint64
+		fetched  int // parents of b fetched by hash
+	}{
+		{"same height", 5, 4, 1, 1, 0},
+		{"shorter chain", 5, 3, 1, 2, 0},
+		{"longer chain", 5, 3, 4, 2, 3},
+		{"deep reorg", 5, 0, 6, 5, 5},
+	}
+	for _, tt := range tests {
+		node := newFakeChainNode()
+		tracker, reorgs := newTestHeadTracker(t, node, nil)
+		a := node.extend(node.genesis(), tt.old, 'a')
+		for _, h := range a {
+			if err := tracker.handle(ctx, h); err != nil {
+				t.Fatalf("%s: handle head %d error %s", tt.name, h.Number, err)
+			}
+		}
+		parent := node.genesis()
+		if tt.ancestor > 0 {
+			parent = a[tt.ancestor-1]
+		}
+		b := node.extend(parent, tt.fork, 'b')
+		head := b[len(b)-1]
+		if err := tracker.handle(ctx, head); err != nil {
+			t.Fatalf("%s: handle reorg error %s", tt.name, err)
+		}
+		if len(*reorgs) != 1 {
+			t.Fatalf("%s: %d reorgs reported, want 1", tt.name, len(*reorgs))
+		}
+		reorg := (*reorgs)[0]
+		if reorg.Depth != tt.depth || reorg.Ancestor != uint64(tt.ancestor) || reorg.OldHead != a[len(a)-1] || reorg.NewHead != head {
+			t.Errorf("%s: reorg depth %d ancestor %d, want %d and %d", tt.name, reorg.Depth, reorg.Ancestor, tt.depth, tt.ancestor)
+		}
+		if node.byHash != tt.fetched {
+			t.Errorf("%s: %d parents fetched by hash, want %d", tt.name, node.byHash, tt.fetched)
+		}
+		// the canonical headers are of the new chain, the blocks above the shorter chain are dropped
+		for _, h := range b {
+			if got, err := tracker.HeaderByNumber(ctx, h.Number.Uint64()); err != nil || got.Hash() != h.Hash() {
+				t.Errorf("%s: header %d of tracker is not of new chain", tt.name, h.Number)
+			}
+		}
+		if head.Number.Uint64() < uint64(tt.old) {
+			if _, err := tracker.HeaderByNumber(ctx, uint64(tt.old)); err == nil {
+				t.Errorf("%s: header %d above the new head should not be found", tt.name, tt.old)
+			}
+		}
+	}
+}
+
+func TestHeadTrackerGap(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeChainNode()
+	tracker, reorgs := newTestHeadTracker(t, node, nil)
+	chain := node.extend(node.genesis(), 6, 'a')
+	if err := tracker.handle(ctx, chain[0]); err != nil {
+		t.Fatalf("handle head error %s", err)
+	}
+	// the missed heads 2..5 are filled by parent hash
+	if err := tracker.handle(ctx, chain[5]); err != nil {
+		t.Fatalf("handle head after gap error %s", err)
+	}
+	if len(*reorgs) != 0 || node.byHash != 4 {
+		t.Fatalf("gap reported %d reorgs and fetched %d parents, want 0 and 4", len(*reorgs), node.byHash)
+	}
+	for _, h := range chain {
+		if got, err := tracker.HeaderByNumber(ctx, h.Number.Uint64()); err != nil || got.Hash() != h.Hash() {
+			t.Fatalf("header %d of tracker got %v error %v", h.Number, got, err)
+		}
+	}
+	if node.byNumber != 0 {
+		t.Fatalf("filled headers fetched %d times by number", node.byNumber)
+	}
+}
+
+func TestHeadTrackerCacheBound(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeChainNode()
+	tracker, _ := newTestHeadTracker(t, node, &HeadTrackerOption{CacheSize: 4})
+	chain := node.extend(node.genesis(), 20, 'a')
+	if err := tracker.handle(ctx, chain[0]); err != nil {
+		t.Fatalf("handle head error %s", err)
+	}
+	// a gap longer than the cache is filled up to the cache size
+	if err := tracker.handle(ctx, chain[19]); err != nil {
+		t.Fatalf("handle head after gap error %s", err)
+	}
+	if node.byHash != 3 {
+		t.Fatalf("gap of cache size 4 fetched %d parents, want 3", node.byHash)
+	}
+	if tracker.byNumber.Len() > 4 || tracker.byHash.Len() > 4 {
+		t.Fatalf("cache of size 4 holds %d numbers %d hashes", tracker.byNumber.Len(), tracker.byHash.Len())
+	}
+	// the evicted headers are fetched from the node
+	if h, err := tracker.HeaderByNumber(ctx, 1); err != nil || h.Hash() != chain[0].Hash() || node.byNumber != 1 {
+		t.Fatalf("evicted header got %v error %v, %d fetched by number", h, err, node.byNumber)
+	}
+}
+
+func TestHeadTrackerStart(t *testing.T) {
+	node := newFakeChainNode()
+	first := node.extend(node.genesis(), 1, 'a')[0]
+	tracker, _ := newTestHeadTracker(t, node, &HeadTrackerOption{Poll: true, PollInterval: time.Millisecond, CheckpointInterval: time.Hour})
+	heads := make(chan *types.Header, 16)
+	tracker.OnNewHead(func(head *types.Header) { heads <- head })
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := tracker.Start(ctx); err != nil {
+		t.Fatalf("start head tracker error %s", err)
+	}
+	<-heads
+	latest := node.extend(first, 3, 'a')[2]
+	for head := range heads {
+		if head.Hash() == latest.Hash() {
+			break
+		}
+	}
+	// the checkpoints are not fetched again for each head within the interval
+	node.lock.Lock()
+	checkpoints := node.checkpoints
+	node.lock.Unlock()
+	if checkpoints != 2 {
+		t.Errorf("safe and finalized heads fetched %d times, want 2", checkpoints)
+	}
+
+	// cancelling the ctx of Start stops the tracking
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		tracker.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("head tracker not stopped by cancelling ctx")
+	}
+}
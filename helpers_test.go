@@ -0,0 +1,24 @@
+package ethclient
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestClient returns a client of a fake JSON-RPC node serving the namespace by service
+func newTestClient(t *testing.T, namespace string, service interface{}) *EthereumClient {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(namespace, service); err != nil {
+		t.Fatalf("register fake %s service error %s", namespace, err)
+	}
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+	c, err := rpc.Dial(hs.URL)
+	if err != nil {
+		t.Fatalf("dial fake node error %s", err)
+	}
+	return newEthereumClient(ethclient.NewClient(c), nil)
+}
@@ -0,0 +1,247 @@
+package ethclient
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+const (
+	StandardScryptN = keystore.StandardScryptN
+	StandardScryptP = keystore.StandardScryptP
+	LightScryptN    = keystore.LightScryptN
+	LightScryptP    = keystore.LightScryptP
+)
+
+// KeyStore manages Web3 Secret Storage v3 key files in a directory
+type KeyStore struct {
+	dir string
+	ks  *keystore.KeyStore
+}
+
+// NewKeyStore open (or create) a key store directory with the given scrypt parameters
+func NewKeyStore(dir string, scryptN, scryptP int) *KeyStore {
+	return &KeyStore{
+		dir: dir,
+		ks:  keystore.NewKeyStore(dir, scryptN, scryptP),
+	}
+}
+
+// NewStandardKeyStore open (or create) a key store directory with the standard scrypt parameters
+func NewStandardKeyStore(dir string) *KeyStore {
+	return NewKeyStore(dir, StandardScryptN, StandardScryptP)
+}
+
+func (m *KeyStore) Dir() string {
+	return m.dir
+}
+
+func (m *KeyStore) KeyStore() *keystore.KeyStore {
+	return m.ks
+}
+
+// Accounts returns the addresses of all key files in the key store directory
+func (m *KeyStore) Accounts() (addrs []string) {
+	for _, acc := range m.ks.Accounts() {
+		addrs = append(addrs, acc.Address.String())
+	}
+	return addrs
+}
+
+func (m *KeyStore) HasAddress(strAddress string) bool {
+	return m.ks.HasAddress(Hex2Address(strAddress))
+}
+
+// NewAccount generate a new key and store it encrypted by passphrase
+func (m *KeyStore) NewAccount(passphrase string) (strAddress string, err error) {
+	var acc accounts.Account
+	acc, err = m.ks.NewAccount(passphrase)
+	if err != nil {
+		return "", err
+	}
+	return acc.Address.String(), nil
+}
+
+// ImportKey import a private key (hex string, bytes or *ecdsa.PrivateKey) and store it encrypted by passphrase
+func (m *KeyStore) ImportKey(privateKey interface{}, passphrase string) (strAddress string, err error) {
+	var pk *ecdsa.PrivateKey
+	pk, err = NewPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	var acc accounts.Account
+	acc, err = m.ks.ImportECDSA(pk, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return acc.Address.String(), nil
+}
+
+// ImportJSON import a v3 key file decrypted by passphrase and re-encrypted by newPassphrase
+func (m *KeyStore) ImportJSON(keyJSON []byte, passphrase, newPassphrase string) (strAddress string, err error) {
+	var acc accounts.Account
+	acc, err = m.ks.Import(keyJSON, passphrase, newPassphrase)
+	if err != nil {
+		return "", err
+	}
+	return acc.Address.String(), nil
+}
+
+// ExportJSON export the key of address as v3 key file encrypted by newPassphrase
+func (m *KeyStore) ExportJSON(strAddress, passphrase, newPassphrase string) (keyJSON []byte, err error) {
+	var acc accounts.Account
+	acc, err = m.find(strAddress)
+	if err != nil {
+		return nil, err
+	}
+	return m.ks.Export(acc, passphrase, newPassphrase)
+}
+
+// Update change the passphrase of address
+func (m *KeyStore) Update(strAddress, passphrase, newPassphrase string) error {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return err
+	}
+	return m.ks.Update(acc, passphrase, newPassphrase)
+}
+
+// Delete remove the key file of address
+func (m *KeyStore) Delete(strAddress, passphrase string) error {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return err
+	}
+	return m.ks.Delete(acc, passphrase)
+}
+
+// Unlock decrypt the key of address and keep it in memory until Lock is called
+func (m *KeyStore) Unlock(strAddress, passphrase string) error {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return err
+	}
+	return m.ks.Unlock(acc, passphrase)
+}
+
+// TimedUnlock decrypt the key of address and keep it in memory for the given duration (0 means until Lock is called)
+func (m *KeyStore) TimedUnlock(strAddress, passphrase string, timeout time.Duration) error {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return err
+	}
+	return m.ks.TimedUnlock(acc, passphrase, timeout)
+}
+
+// Lock remove the decrypted key of address from memory
+func (m *KeyStore) Lock(strAddress string) error {
+	return m.ks.Lock(Hex2Address(strAddress))
+}
+
+// SignHash sign hash by an unlocked account
+func (m *KeyStore) SignHash(strAddress string, hash []byte) ([]byte, error) {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return nil, err
+	}
+	return m.ks.SignHash(acc, hash)
+}
+
+// SignTx sign transaction by an unlocked account
+func (m *KeyStore) SignTx(strAddress string, tx *types.Transaction, chainId int64) (*types.Transaction, error) {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return nil, err
+	}
+	return m.ks.SignTx(acc, tx, big.NewInt(chainId))
+}
+
+// NewTransactOpts new transact options signed by an unlocked account and chain id
+func (m *KeyStore) NewTransactOpts(strAddress string, chainId int64) (txOpts *bind.TransactOpts, err error) {
+	var acc accounts.Account
+	acc, err = m.find(strAddress)
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewKeyStoreTransactorWithChainID(m.ks, acc, big.NewInt(chainId))
+}
+
+// NewTransactOptsWithValue new transact options signed by an unlocked account and chain id and value
+func (m *KeyStore) NewTransactOptsWithValue(strAddress string, chainId int64, value interface{}) (txOpts *bind.TransactOpts, err error) {
+	if value == nil {
+		return nil, fmt.Errorf("value must be non-nil")
+	}
+	var bigValue *big.Int
+	bigValue, err = parseBigValue(value)
+	if err != nil {
+		return nil, err
+	}
+	txOpts, err = m.NewTransactOpts(strAddress, chainId)
+	if err != nil {
+		return nil, err
+	}
+	txOpts.Value = bigValue
+	return txOpts, nil
+}
+
+func (m *KeyStore) find(strAddress string) (acc accounts.Account, err error) {
+	acc, err = m.ks.Find(accounts.Account{Address: Hex2Address(strAddress)})
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("find account [%s] error [%s]", strAddress, err)
+	}
+	return acc, nil
+}
+
+// EncryptKeyJSON encrypt a private key (hex string, bytes or *ecdsa.PrivateKey) into a v3 key file
+func EncryptKeyJSON(privateKey interface{}, passphrase string, scryptN, scryptP int) (keyJSON []byte, err error) {
+	var pk *ecdsa.PrivateKey
+	pk, err = NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var id uuid.UUID
+	id, err = uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(pk.PublicKey),
+		PrivateKey: pk,
+	}
+	return keystore.EncryptKey(key, passphrase, scryptN, scryptP)
+}
+
+// DecryptKeyJSON decrypt a v3 key file by passphrase
+func DecryptKeyJSON(keyJSON []byte, passphrase string) (pk *ecdsa.PrivateKey, err error) {
+	var key *keystore.Key
+	key, err = keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+// KeyJSONAddress returns the address recorded in a v3 key file without decrypting it
+func KeyJSONAddress(keyJSON []byte) (common.Address, error) {
+	var v struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &v); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(v.Address) {
+		return common.Address{}, fmt.Errorf("key json address [%s] invalid", v.Address)
+	}
+	return common.HexToAddress(v.Address), nil
+}
@@ -0,0 +1,69 @@
+package ethclient
+
+import (
+	"testing"
+)
+
+const (
+	testPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testAddress    = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+func TestKeyStoreImportExport(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	addr, err := ks.ImportKey(testPrivateKey, "foo")
+	if err != nil {
+		t.Fatalf("import key error %s", err)
+	}
+	if addr != testAddress {
+		t.Fatalf("import key address %s, want %s", addr, testAddress)
+	}
+	if accs := ks.Accounts(); len(accs) != 1 || accs[0] != testAddress {
+		t.Fatalf("accounts %v, want [%s]", accs, testAddress)
+	}
+	keyJSON, err := ks.ExportJSON(addr, "foo", "bar")
+	if err != nil {
+		t.Fatalf("export key error %s", err)
+	}
+	if _, err = DecryptKeyJSON(keyJSON, "foo"); err == nil {
+		t.Fatalf("decrypt exported key with old passphrase should fail")
+	}
+	pk, err := DecryptKeyJSON(keyJSON, "bar")
+	if err != nil {
+		t.Fatalf("decrypt exported key error %s", err)
+	}
+	want, _ := NewPrivateKey(testPrivateKey)
+	if !pk.Equal(want) {
+		t.Fatalf("decrypted key mismatch")
+	}
+}
+
+func TestKeyStoreTransactOpts(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), LightScryptN, LightScryptP)
+	keyJSON, err := EncryptKeyJSON(testPrivateKey, "foo", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("encrypt key error %s", err)
+	}
+	if addr, _ := KeyJSONAddress(keyJSON); addr.String() != testAddress {
+		t.Fatalf("key json address %s, want %s", addr, testAddress)
+	}
+	if _, err = ks.ImportJSON(keyJSON, "foo", "foo"); err != nil {
+		t.Fatalf("import json error %s", err)
+	}
+	opts, err := ks.NewTransactOptsWithValue(testAddress, 1, "1000")
+	if err != nil {
+		t.Fatalf("new transact opts error %s", err)
+	}
+	if opts.From.String() != testAddress || opts.Value.Int64() != 1000 {
+		t.Fatalf("transact opts from %s value %s", opts.From, opts.Value)
+	}
+	if _, err = ks.SignHash(testAddress, make([]byte, 32)); err == nil {
+		t.Fatalf("sign by locked account should fail")
+	}
+	if err = ks.Unlock(testAddress, "foo"); err != nil {
+		t.Fatalf("unlock error %s", err)
+	}
+	if _, err = ks.SignHash(testAddress, make([]byte, 32)); err != nil {
+		t.Fatalf("sign by unlocked account error %s", err)
+	}
+}
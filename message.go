@@ -0,0 +1,85 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const eip1271ABI = `[{"inputs":[{"internalType":"bytes32","name":"hash","type":"bytes32"},{"internalType":"bytes","name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"internalType":"bytes4","name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	// eip1271MagicValue is bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+	eip1271MagicValue  = []byte{0x16, 0x26, 0xba, 0x7e}
+	eip1271ContractABI = mustLoadABI(eip1271ABI)
+)
+
+// HashMessage returns the EIP-191 personal message hash keccak256("\x19Ethereum Signed Message:\n" || len(msg) || msg)
+func HashMessage(msg []byte) common.Hash {
+	return common.BytesToHash(accounts.TextHash(msg))
+}
+
+// SignMessage sign message as personal_sign by private key (hex string, bytes or *ecdsa.PrivateKey) or Signer,
+// the signature V is 27 or 28
+func SignMessage(signer interface{}, msg []byte) ([]byte, error) {
+	return signHash(signer, HashMessage(msg).Bytes())
+}
+
+// RecoverMessage recover the signer address of a personal_sign signature
+func RecoverMessage(msg []byte, sig []byte) (common.Address, error) {
+	return RecoverHash(HashMessage(msg).Bytes(), sig)
+}
+
+// VerifyMessage check the personal_sign signature was signed by address
+func VerifyMessage(msg []byte, sig []byte, strAddress string) (bool, error) {
+	addr, err := RecoverMessage(msg, sig)
+	if err != nil {
+		return false, err
+	}
+	return addr == Hex2Address(strAddress), nil
+}
+
+// IsValidSignature call EIP-1271 isValidSignature(hash, signature) of a smart-contract wallet at latest block
+func (m *EthereumClient) IsValidSignature(ctx context.Context, strContract string, hash common.Hash, sig []byte) (bool, error) {
+	data, err := eip1271ContractABI.Pack("isValidSignature", hash, sig)
+	if err != nil {
+		return false, err
+	}
+	contract := Hex2Address(strContract)
+	var output []byte
+	output, err = m.ethcli.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("call isValidSignature of contract [%s] error [%s]", strContract, err)
+	}
+	if len(output) < len(eip1271MagicValue) {
+		return false, nil
+	}
+	return bytes.Equal(output[:len(eip1271MagicValue)], eip1271MagicValue), nil
+}
+
+// VerifyHashSignature check the signature of hash was signed by address, the signature is recovered
+// if address is an EOA or checked by EIP-1271 if address is a contract
+func (m *EthereumClient) VerifyHashSignature(ctx context.Context, strAddress string, hash common.Hash, sig []byte) (bool, error) {
+	code, err := m.ethcli.CodeAt(ctx, Hex2Address(strAddress), nil)
+	if err != nil {
+		return false, fmt.Errorf("get code of [%s] error [%s]", strAddress, err)
+	}
+	if len(code) != 0 {
+		return m.IsValidSignature(ctx, strAddress, hash, sig)
+	}
+	var addr common.Address
+	addr, err = RecoverHash(hash.Bytes(), sig)
+	if err != nil {
+		return false, err
+	}
+	return addr == Hex2Address(strAddress), nil
+}
+
+// VerifyMessageSignature check the personal_sign signature of message was signed by an EOA or a smart-contract wallet
+func (m *EthereumClient) VerifyMessageSignature(ctx context.Context, strAddress string, msg []byte, sig []byte) (bool, error) {
+	return m.VerifyHashSignature(ctx, strAddress, HashMessage(msg), sig)
+}
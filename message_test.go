@@ -0,0 +1,82 @@
+package ethclient
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// example from web3.eth.accounts.sign
+const (
+	testMessage          = "Some data"
+	testMessageHash      = "0x1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"
+	testMessageSignature = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+)
+
+func TestSignMessage(t *testing.T) {
+	if hash := HashMessage([]byte(testMessage)); hash.Hex() != testMessageHash {
+		t.Fatalf("message hash %s, want %s", hash.Hex(), testMessageHash)
+	}
+	sig, err := SignMessage(testPrivateKey, []byte(testMessage))
+	if err != nil {
+		t.Fatalf("sign message error %s", err)
+	}
+	if hexutil.Encode(sig) != testMessageSignature {
+		t.Fatalf("message signature %s, want %s", hexutil.Encode(sig), testMessageSignature)
+	}
+}
+
+// fakeSigner signs by a private key and adds offset to V like the signers returning V in {27, 28}
+type fakeSigner struct {
+	Signer
+	offset byte
+}
+
+func (s *fakeSigner) SignHash(hash []byte) ([]byte, error) {
+	sig, err := s.Signer.SignHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += s.offset
+	return sig, nil
+}
+
+func TestSignMessageSigner(t *testing.T) {
+	signer, err := NewSigner(testPrivateKey)
+	if err != nil {
+		t.Fatalf("new signer error %s", err)
+	}
+	for _, offset := range []byte{0, 27} {
+		sig, err := SignMessage(&fakeSigner{Signer: signer, offset: offset}, []byte(testMessage))
+		if err != nil {
+			t.Fatalf("sign message by signer of v offset %d error %s", offset, err)
+		}
+		if hexutil.Encode(sig) != testMessageSignature {
+			t.Fatalf("message signature of v offset %d is %s, want %s", offset, hexutil.Encode(sig), testMessageSignature)
+		}
+	}
+	if _, err = SignMessage(&fakeSigner{Signer: signer, offset: 2}, []byte(testMessage)); err == nil {
+		t.Fatalf("sign message by signer of invalid v should fail")
+	}
+}
+
+func TestRecoverMessage(t *testing.T) {
+	sig := hexutil.MustDecode(testMessageSignature)
+	for _, v := range []byte{27, 28, 0, 1} {
+		sig[64] = v
+		addr, err := RecoverMessage([]byte(testMessage), sig)
+		if err != nil {
+			t.Fatalf("recover message with v %d error %s", v, err)
+		}
+		if ok := addr.String() == testAddress; ok != (v == 28 || v == 1) {
+			t.Fatalf("recover message with v %d got %s", v, addr)
+		}
+	}
+	sig[64] = 29
+	if _, err := RecoverMessage([]byte(testMessage), sig); err == nil {
+		t.Fatalf("recover message with v 29 should fail")
+	}
+	if ok, _ := VerifyMessage([]byte("other data"), hexutil.MustDecode(testMessageSignature), testAddress); ok {
+		t.Fatalf("verify other message should fail")
+	}
+}
@@ -0,0 +1,223 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Multicall3Address is the address of Multicall3 deployed on most EVM chains
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	multicallDefaultBatchSize    = 500
+	multicallDefaultCalldataSize = 128 * 1024
+)
+
+const multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var multicall3ContractABI = mustLoadABI(multicall3ABI)
+
+// MulticallCall is a view method call to aggregate
+type MulticallCall struct {
+	Target       string
+	ABI          abi.ABI
+	Method       string
+	Args         []interface{}
+	AllowFailure bool
+}
+
+// MulticallResult is the result of a MulticallCall, Values are the outputs unpacked by the call ABI
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+	Values     []interface{}
+	Err        error
+}
+
+// MulticallOption controls how calls are aggregated
+type MulticallOption struct {
+	Address      string   // Multicall3 contract address, default Multicall3Address
+	BatchSize    int      // max calls per aggregate3, default 500
+	CalldataSize int      // max aggregate3 calldata bytes, default 128KiB
+	Gas          uint64   // gas limit of each aggregate3 call, 0 means node default
+	BlockNumber  *big.Int // block to call at, nil means latest
+}
+
+// multicall3Call is the Multicall3.Call3 tuple
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the Multicall3.Result tuple
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// NewMulticallCall new a view method call of contract to aggregate, the call fails the whole multicall if it reverts
+func NewMulticallCall(strTarget string, contractABI abi.ABI, method string, args ...interface{}) *MulticallCall {
+	return &MulticallCall{
+		Target: strTarget,
+		ABI:    contractABI,
+		Method: method,
+		Args:   args,
+	}
+}
+
+// Unpack copy the unpacked outputs into v
+func (r *MulticallResult) Unpack(call *MulticallCall, v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if !r.Success {
+		return fmt.Errorf("call method [%s] of contract [%s] failed", call.Method, call.Target)
+	}
+	return call.ABI.UnpackIntoInterface(v, call.Method, r.ReturnData)
+}
+
+// Multicall aggregate the view calls into Multicall3 aggregate3 calls split by batch and calldata size, a batch
+// that fails as a whole (e.g. out of gas) is split again. It falls back to individual eth_call when Multicall3
+// is not deployed on the chain. An error is returned if a call without AllowFailure fails.
+func (m *EthereumClient) Multicall(ctx context.Context, calls []*MulticallCall, opt *MulticallOption) (results []*MulticallResult, err error) {
+	if opt == nil {
+		opt = &MulticallOption{}
+	}
+	address := opt.Address
+	if address == "" {
+		address = Multicall3Address
+	}
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = multicallDefaultBatchSize
+	}
+	calldataSize := opt.CalldataSize
+	if calldataSize <= 0 {
+		calldataSize = multicallDefaultCalldataSize
+	}
+
+	packed := make([]multicall3Call, len(calls))
+	for i, call := range calls {
+		var data []byte
+		data, err = call.ABI.Pack(call.Method, call.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("pack method [%s] of contract [%s] error [%s]", call.Method, call.Target, err)
+		}
+		packed[i] = multicall3Call{
+			Target:       Hex2Address(call.Target),
+			AllowFailure: call.AllowFailure,
+			CallData:     data,
+		}
+	}
+
+	var code []byte
+	code, err = m.ethcli.CodeAt(ctx, Hex2Address(address), opt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("get multicall code of [%s] error [%s]", address, err)
+	}
+	mc := &multicaller{
+		cli:      m,
+		address:  Hex2Address(address),
+		opt:      opt,
+		fallback: len(code) == 0,
+		results:  make([]*MulticallResult, len(calls)),
+	}
+
+	start, size := 0, 0
+	for i := range packed {
+		// 32 bytes offset + 3 * 32 bytes head + padded calldata
+		callSize := 4*32 + (len(packed[i].CallData)+31)/32*32
+		if i > start && (i-start >= batchSize || size+callSize > calldataSize) {
+			if err = mc.aggregate(ctx, packed, start, i); err != nil {
+				return nil, err
+			}
+			start, size = i, 0
+		}
+		size += callSize
+	}
+	if start < len(packed) {
+		if err = mc.aggregate(ctx, packed, start, len(packed)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, r := range mc.results {
+		if r.Success && len(calls[i].ABI.Methods) != 0 {
+			r.Values, r.Err = calls[i].ABI.Unpack(calls[i].Method, r.ReturnData)
+		}
+	}
+	return mc.results, nil
+}
+
+type multicaller struct {
+	cli      *EthereumClient
+	address  common.Address
+	opt      *MulticallOption
+	fallback bool
+	results  []*MulticallResult
+}
+
+// aggregate call packed[start:end] by aggregate3, the range is halved if the aggregated call fails
+func (mc *multicaller) aggregate(ctx context.Context, packed []multicall3Call, start, end int) error {
+	if mc.fallback || end-start == 1 {
+		for i := start; i < end; i++ {
+			if err := mc.single(ctx, packed, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	data, err := multicall3ContractABI.Pack("aggregate3", packed[start:end])
+	if err != nil {
+		return err
+	}
+	var output []byte
+	output, err = mc.cli.ethcli.CallContract(ctx, ethereum.CallMsg{To: &mc.address, Gas: mc.opt.Gas, Data: data}, mc.opt.BlockNumber)
+	if err != nil {
+		mid := start + (end-start)/2
+		if err = mc.aggregate(ctx, packed, start, mid); err != nil {
+			return err
+		}
+		return mc.aggregate(ctx, packed, mid, end)
+	}
+	var values []interface{}
+	values, err = multicall3ContractABI.Unpack("aggregate3", output)
+	if err != nil {
+		return fmt.Errorf("unpack aggregate3 output error [%s]", err)
+	}
+	returns := *abi.ConvertType(values[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(returns) != end-start {
+		return fmt.Errorf("aggregate3 returns %d results, want %d", len(returns), end-start)
+	}
+	for i, r := range returns {
+		mc.results[start+i] = &MulticallResult{
+			Success:    r.Success,
+			ReturnData: r.ReturnData,
+		}
+	}
+	return nil
+}
+
+// single call packed[i] by eth_call directly
+func (mc *multicaller) single(ctx context.Context, packed []multicall3Call, i int) error {
+	call := packed[i]
+	output, err := mc.cli.ethcli.CallContract(ctx, ethereum.CallMsg{To: &call.Target, Gas: mc.opt.Gas, Data: call.CallData}, mc.opt.BlockNumber)
+	if err != nil {
+		if !call.AllowFailure {
+			return fmt.Errorf("call contract [%s] error [%s]", call.Target, err)
+		}
+		mc.results[i] = &MulticallResult{Err: err}
+		return nil
+	}
+	mc.results[i] = &MulticallResult{
+		Success:    true,
+		ReturnData: output,
+	}
+	return nil
+}
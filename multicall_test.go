@@ -0,0 +1,157 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var multicallTargetABI = mustParseHumanReadableABI(
+	"function balanceOf(address owner) view returns (uint256)",
+	"function fail() view",
+)
+
+func mustParseHumanReadableABI(signatures ...string) abi.ABI {
+	contractABI, err := ParseHumanReadableABI(signatures...)
+	if err != nil {
+		panic(err)
+	}
+	return contractABI
+}
+
+// fakeMulticallNode serve eth_call of Multicall3 aggregate3 and of a token whose balanceOf(owner) returns the last
+// byte of owner and fail() reverts. An aggregate3 of more than maxCalls calls runs out of gas
+type fakeMulticallNode struct {
+	deployed  bool
+	maxCalls  int
+	aggregate int // count of aggregate3 calls
+	single    int // count of direct calls
+}
+
+type fakeCallArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+func (f *fakeMulticallNode) GetCode(addr common.Address, block string) hexutil.Bytes {
+	if f.deployed && addr == Hex2Address(Multicall3Address) {
+		return hexutil.Bytes{0x60, 0x80}
+	}
+	return hexutil.Bytes{}
+}
+
+func (f *fakeMulticallNode) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	if args.To != Hex2Address(Multicall3Address) {
+		f.single++
+		return f.target(args.Data)
+	}
+	f.aggregate++
+	values, err := multicall3ContractABI.Methods["aggregate3"].Inputs.Unpack(args.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := *abi.ConvertType(values[0], new([]multicall3Call)).(*[]multicall3Call)
+	if len(calls) > f.maxCalls {
+		return nil, fmt.Errorf("out of gas")
+	}
+	results := make([]multicall3Result, len(calls))
+	for i, call := range calls {
+		output, err := f.target(call.CallData)
+		if err != nil && !call.AllowFailure {
+			return nil, fmt.Errorf("execution reverted: Multicall3: call failed")
+		}
+		results[i] = multicall3Result{Success: err == nil, ReturnData: output}
+	}
+	return multicall3ContractABI.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+func (f *fakeMulticallNode) target(data []byte) (hexutil.Bytes, error) {
+	method, err := multicallTargetABI.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "fail" {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	owner := values[0].(common.Address)
+	return method.Outputs.Pack(big.NewInt(int64(owner[19])))
+}
+
+func TestMulticall(t *testing.T) {
+	token := "0x00000000000000000000000000000000000000c0"
+	var calls []*MulticallCall
+	for i := 1; i <= 7; i++ {
+		calls = append(calls, NewMulticallCall(token, multicallTargetABI, "balanceOf", common.BigToAddress(big.NewInt(int64(i)))))
+	}
+	failing := NewMulticallCall(token, multicallTargetABI, "fail")
+	failing.AllowFailure = true
+	calls = append(calls[:3], append([]*MulticallCall{failing}, calls[3:]...)...)
+
+	tests := []struct {
+		name      string
+		node      *fakeMulticallNode
+		opt       *MulticallOption
+		aggregate int
+		single    int
+	}{
+		{"one batch", &fakeMulticallNode{deployed: true, maxCalls: 100}, nil, 1, 0},
+		{"batch size", &fakeMulticallNode{deployed: true, maxCalls: 100}, &MulticallOption{BatchSize: 3}, 3, 0},
+		// balanceOf calls of 192 bytes and fail of 160 bytes split into 2 calls per batch
+		{"calldata size", &fakeMulticallNode{deployed: true, maxCalls: 100}, &MulticallOption{CalldataSize: 2 * 192}, 4, 0},
+		// out of gas batches are halved: 8 fails, 4 + 4 succeed
+		{"out of gas", &fakeMulticallNode{deployed: true, maxCalls: 4}, nil, 3, 0},
+		{"not deployed", &fakeMulticallNode{}, nil, 0, 8},
+	}
+	for _, tt := range tests {
+		m := newTestClient(t, "eth", tt.node)
+		results, err := m.Multicall(context.Background(), calls, tt.opt)
+		if err != nil {
+			t.Fatalf("%s: multicall error %s", tt.name, err)
+		}
+		if tt.node.aggregate != tt.aggregate || tt.node.single != tt.single {
+			t.Errorf("%s: %d aggregate3 and %d single calls, want %d and %d", tt.name, tt.node.aggregate, tt.node.single, tt.aggregate, tt.single)
+		}
+		if len(results) != len(calls) {
+			t.Fatalf("%s: %d results of %d calls", tt.name, len(results), len(calls))
+		}
+		for i, r := range results {
+			if calls[i] == failing {
+				if r.Success || r.Values != nil {
+					t.Errorf("%s: failed call %d decoded as success", tt.name, i)
+				}
+				var v struct{}
+				if err = r.Unpack(calls[i], &v); err == nil {
+					t.Errorf("%s: unpack failed call %d should fail", tt.name, i)
+				}
+				continue
+			}
+			owner := calls[i].Args[0].(common.Address)
+			if !r.Success || r.Err != nil || len(r.Values) != 1 || r.Values[0].(*big.Int).Int64() != int64(owner[19]) {
+				t.Errorf("%s: result %d is %+v", tt.name, i, r)
+			}
+			var balance *big.Int
+			if err = r.Unpack(calls[i], &balance); err != nil || balance.Int64() != int64(owner[19]) {
+				t.Errorf("%s: unpack result %d got %v error %v", tt.name, i, balance, err)
+			}
+		}
+	}
+
+	// a failed call without allowFailure fails the multicall
+	failing.AllowFailure = false
+	for _, node := range []*fakeMulticallNode{{deployed: true, maxCalls: 100}, {}} {
+		m := newTestClient(t, "eth", node)
+		if _, err := m.Multicall(context.Background(), calls, nil); err == nil || !strings.Contains(err.Error(), "call contract") {
+			t.Errorf("multicall of failed call without allow failure should fail, got error %v", err)
+		}
+	}
+}
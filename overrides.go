@@ -0,0 +1,160 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// OverrideAccount is the state override of an account for eth_call, nil fields are not overridden.
+// State replaces the whole storage of the account while StateDiff only replaces the given slots
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+// BlockOverrides is the block context override for eth_call, nil fields are not overridden. The fields are sent by
+// the geth names number, difficulty, time, gasLimit, coinbase, random and baseFee; for nodes naming them otherwise
+// (e.g. feeRecipient, prevRandao or baseFeePerGas) leave the field nil and set the value in Extra by that name
+type BlockOverrides struct {
+	Number     *big.Int
+	Difficulty *big.Int
+	Time       *uint64
+	GasLimit   *uint64
+	Coinbase   *common.Address
+	Random     *common.Hash
+	BaseFee    *big.Int
+	Extra      map[string]interface{} // sent as is
+}
+
+func (a *OverrideAccount) MarshalJSON() ([]byte, error) {
+	type override struct {
+		Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+		Code      *hexutil.Bytes              `json:"code,omitempty"`
+		Balance   *hexutil.Big                `json:"balance,omitempty"`
+		State     map[common.Hash]common.Hash `json:"state,omitempty"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+	}
+	o := override{
+		Nonce:     (*hexutil.Uint64)(a.Nonce),
+		Balance:   (*hexutil.Big)(a.Balance),
+		State:     a.State,
+		StateDiff: a.StateDiff,
+	}
+	if a.Code != nil {
+		code := hexutil.Bytes(a.Code)
+		o.Code = &code
+	}
+	return json.Marshal(o)
+}
+
+func (b *BlockOverrides) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(b.Extra)+7)
+	for name, value := range b.Extra {
+		fields[name] = value
+	}
+	if b.Number != nil {
+		fields["number"] = (*hexutil.Big)(b.Number)
+	}
+	if b.Difficulty != nil {
+		fields["difficulty"] = (*hexutil.Big)(b.Difficulty)
+	}
+	if b.Time != nil {
+		fields["time"] = hexutil.Uint64(*b.Time)
+	}
+	if b.GasLimit != nil {
+		fields["gasLimit"] = hexutil.Uint64(*b.GasLimit)
+	}
+	if b.Coinbase != nil {
+		fields["coinbase"] = b.Coinbase
+	}
+	if b.Random != nil {
+		fields["random"] = b.Random
+	}
+	if b.BaseFee != nil {
+		fields["baseFee"] = (*hexutil.Big)(b.BaseFee)
+	}
+	return json.Marshal(fields)
+}
+
+// CallContractWithOverrides eth_call at block number with state overrides (address => override) and block overrides
+func (m *EthereumClient) CallContractWithOverrides(ctx context.Context, msg ethereum.CallMsg, number uint64, overrides map[string]*OverrideAccount, blockOverrides *BlockOverrides) ([]byte, error) {
+	return m.callContractWithOverrides(ctx, msg, toBlockNumArg(Uint642Big(number)), overrides, blockOverrides)
+}
+
+// CallContractAtHashWithOverrides eth_call at block hash with state overrides (address => override) and block overrides
+func (m *EthereumClient) CallContractAtHashWithOverrides(ctx context.Context, msg ethereum.CallMsg, strBlockHash string, overrides map[string]*OverrideAccount, blockOverrides *BlockOverrides) ([]byte, error) {
+	return m.callContractWithOverrides(ctx, msg, rpc.BlockNumberOrHashWithHash(Hex2Hash(strBlockHash), false), overrides, blockOverrides)
+}
+
+// PendingCallContractWithOverrides eth_call at pending state with state overrides (address => override) and block overrides
+func (m *EthereumClient) PendingCallContractWithOverrides(ctx context.Context, msg ethereum.CallMsg, overrides map[string]*OverrideAccount, blockOverrides *BlockOverrides) ([]byte, error) {
+	return m.callContractWithOverrides(ctx, msg, "pending", overrides, blockOverrides)
+}
+
+func (m *EthereumClient) callContractWithOverrides(ctx context.Context, msg ethereum.CallMsg, block interface{}, overrides map[string]*OverrideAccount, blockOverrides *BlockOverrides) ([]byte, error) {
+	args := []interface{}{toCallArg(msg), block, toStateOverrides(overrides)}
+	if blockOverrides != nil {
+		args = append(args, blockOverrides)
+	}
+	var hex hexutil.Bytes
+	if err := m.ethcli.Client().CallContext(ctx, &hex, "eth_call", args...); err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
+func toStateOverrides(overrides map[string]*OverrideAccount) map[common.Address]*OverrideAccount {
+	state := make(map[common.Address]*OverrideAccount, len(overrides))
+	for strAddress, o := range overrides {
+		state[Hex2Address(strAddress)] = o
+	}
+	return state
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	return rpc.BlockNumber(number.Int64()).String()
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
@@ -0,0 +1,36 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlockOverridesMarshalJSON(t *testing.T) {
+	time, gasLimit := uint64(1700000000), uint64(30000000)
+	coinbase := common.HexToAddress("0x01")
+	random := common.HexToHash("0x02")
+	tests := []struct {
+		overrides *BlockOverrides
+		want      string
+	}{
+		{&BlockOverrides{}, `{}`},
+		{&BlockOverrides{Number: big.NewInt(16), Time: &time, GasLimit: &gasLimit, Coinbase: &coinbase, Random: &random, BaseFee: big.NewInt(7)},
+			`{"baseFee":"0x7","coinbase":"0x0000000000000000000000000000000000000001","gasLimit":"0x1c9c380","number":"0x10",` +
+				`"random":"0x0000000000000000000000000000000000000000000000000000000000000002","time":"0x6553f100"}`},
+		// the other spelling is chosen by the caller
+		{&BlockOverrides{Number: big.NewInt(1), Extra: map[string]interface{}{"feeRecipient": coinbase, "baseFeePerGas": "0x7"}},
+			`{"baseFeePerGas":"0x7","feeRecipient":"0x0000000000000000000000000000000000000001","number":"0x1"}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.overrides)
+		if err != nil {
+			t.Fatalf("marshal block overrides error %s", err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("marshal block overrides got %s, want %s", data, tt.want)
+		}
+	}
+}
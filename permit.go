@@ -0,0 +1,190 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const erc20PermitABI = `[
+{"inputs":[],"name":"name","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"internalType":"address","name":"owner","type":"address"}],"name":"nonces","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"uint256","name":"deadline","type":"uint256"},{"internalType":"uint8","name":"v","type":"uint8"},{"internalType":"bytes32","name":"r","type":"bytes32"},{"internalType":"bytes32","name":"s","type":"bytes32"}],"name":"permit","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+const (
+	permitPrimaryType    = "Permit"
+	permitDefaultVersion = "1"
+)
+
+var erc20PermitContractABI = mustLoadABI(erc20PermitABI)
+
+var permitTypes = apitypes.Types{
+	permitPrimaryType: {
+		{Name: "owner", Type: "address"},
+		{Name: "spender", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+// Permit is a signed EIP-2612 permit ready to be passed to permit(owner, spender, value, deadline, v, r, s)
+type Permit struct {
+	Token     common.Address
+	Owner     common.Address
+	Spender   common.Address
+	Value     *big.Int
+	Nonce     *big.Int
+	Deadline  *big.Int
+	V         uint8
+	R         [32]byte
+	S         [32]byte
+	Signature []byte
+}
+
+// Pack returns the calldata of permit(owner, spender, value, deadline, v, r, s)
+func (p *Permit) Pack() ([]byte, error) {
+	return erc20PermitContractABI.Pack("permit", p.Owner, p.Spender, p.Value, p.Deadline, p.V, p.R, p.S)
+}
+
+// BuildPermit read name, version and nonces of the token and build the EIP-2612 Permit typed data of owner
+// for spender, value and deadline; the domain separator is checked against DOMAIN_SEPARATOR() when the token has it.
+// A token whose version() reverts or is missing is assumed of version "1", which must be confirmed by its
+// DOMAIN_SEPARATOR(); other errors of the calls are returned
+func (m *EthereumClient) BuildPermit(ctx context.Context, strToken, strOwner, strSpender string, value, deadline *big.Int) (td *apitypes.TypedData, err error) {
+	if value == nil || deadline == nil {
+		return nil, fmt.Errorf("permit value and deadline must not be nil")
+	}
+	var chainId int64
+	chainId, err = m.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	values, err = m.callView(ctx, erc20PermitContractABI, strToken, "name")
+	if err != nil {
+		return nil, err
+	}
+	name := values[0].(string)
+	version, assumed := permitDefaultVersion, true
+	var ok bool
+	if values, ok, err = m.callOptionalView(ctx, erc20PermitContractABI, strToken, "version"); err != nil {
+		return nil, err
+	} else if ok {
+		version, assumed = values[0].(string), false
+	}
+	values, err = m.callView(ctx, erc20PermitContractABI, strToken, "nonces", Hex2Address(strOwner))
+	if err != nil {
+		return nil, err
+	}
+	nonce := values[0].(*big.Int)
+
+	td = NewTypedData(permitPrimaryType,
+		apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           math.NewHexOrDecimal256(chainId),
+			VerifyingContract: Hex2Address(strToken).String(),
+		},
+		permitTypes,
+		map[string]interface{}{
+			"owner":    Hex2Address(strOwner).String(),
+			"spender":  Hex2Address(strSpender).String(),
+			"value":    value,
+			"nonce":    nonce,
+			"deadline": deadline,
+		})
+
+	if values, ok, err = m.callOptionalView(ctx, erc20PermitContractABI, strToken, "DOMAIN_SEPARATOR"); err != nil {
+		return nil, err
+	} else if !ok {
+		if assumed {
+			return nil, fmt.Errorf("token [%s] has neither version() nor DOMAIN_SEPARATOR(), the assumed version [%s] can not be checked",
+				strToken, version)
+		}
+		return td, nil
+	}
+	var domainSeparator common.Hash
+	domainSeparator, err = TypedDataDomainSeparator(td)
+	if err != nil {
+		return nil, err
+	}
+	if onchain := common.Hash(values[0].([32]byte)); onchain != domainSeparator {
+		if assumed {
+			return nil, fmt.Errorf("token [%s] has no version() and DOMAIN_SEPARATOR %s mismatch with the assumed version [%s]",
+				strToken, onchain.Hex(), version)
+		}
+		return nil, fmt.Errorf("token [%s] DOMAIN_SEPARATOR %s mismatch with name [%s] version [%s] chain id [%d] domain %s",
+			strToken, onchain.Hex(), name, version, chainId, domainSeparator.Hex())
+	}
+	return td, nil
+}
+
+// callOptionalView call the view method of contract without arguments, ok is false if the call reverts or returns
+// nothing (the method is missing)
+func (m *EthereumClient) callOptionalView(ctx context.Context, contractABI abi.ABI, strContract string, method string) (values []interface{}, ok bool, err error) {
+	var data, output []byte
+	data, err = contractABI.Pack(method)
+	if err != nil {
+		return nil, false, err
+	}
+	contract := Hex2Address(strContract)
+	output, err = m.ethcli.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if isReverted(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("call method [%s] of contract [%s] error [%s]", method, strContract, err)
+	}
+	if len(output) == 0 {
+		return nil, false, nil
+	}
+	values, err = contractABI.Unpack(method, output)
+	if err != nil {
+		return nil, false, fmt.Errorf("unpack method [%s] output of contract [%s] error [%s]", method, strContract, err)
+	}
+	return values, true, nil
+}
+
+// SignPermit build and sign the EIP-2612 permit of token for spender, value and deadline by private key
+// (hex string, bytes or *ecdsa.PrivateKey) or Signer, the owner is the signer address
+func (m *EthereumClient) SignPermit(ctx context.Context, signer interface{}, strToken, strSpender string, value, deadline *big.Int) (permit *Permit, err error) {
+	var s Signer
+	s, err = NewSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+	var td *apitypes.TypedData
+	td, err = m.BuildPermit(ctx, strToken, s.Address().String(), strSpender, value, deadline)
+	if err != nil {
+		return nil, err
+	}
+	var sig []byte
+	sig, err = SignTypedData(s, td)
+	if err != nil {
+		return nil, err
+	}
+	permit = &Permit{
+		Token:     Hex2Address(strToken),
+		Owner:     s.Address(),
+		Spender:   Hex2Address(strSpender),
+		Value:     value,
+		Nonce:     td.Message["nonce"].(*big.Int),
+		Deadline:  deadline,
+		V:         sig[crypto.RecoveryIDOffset],
+		Signature: sig,
+	}
+	copy(permit.R[:], sig[:32])
+	copy(permit.S[:], sig[32:64])
+	return permit, nil
+}
@@ -0,0 +1,146 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// fakePermitToken serve an EIP-2612 token of chain 1, version() and DOMAIN_SEPARATOR() revert if not set
+type fakePermitToken struct {
+	token         common.Address
+	version       string // version() returns
+	domainVersion string // DOMAIN_SEPARATOR() is of
+	missing       bool   // version() returns nothing as of a fallback function
+	versionErr    error  // version() fails with
+}
+
+func (f *fakePermitToken) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (f *fakePermitToken) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	method, err := erc20PermitContractABI.MethodById(args.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "name":
+		return method.Outputs.Pack("Token")
+	case "version":
+		if f.versionErr != nil {
+			return nil, f.versionErr
+		}
+		if f.missing {
+			return nil, nil
+		}
+		if f.version == "" {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		return method.Outputs.Pack(f.version)
+	case "nonces":
+		return method.Outputs.Pack(big.NewInt(3))
+	case "DOMAIN_SEPARATOR":
+		if f.domainVersion == "" {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		td := NewTypedData(permitPrimaryType, apitypes.TypedDataDomain{
+			Name:              "Token",
+			Version:           f.domainVersion,
+			ChainId:           math.NewHexOrDecimal256(1),
+			VerifyingContract: f.token.String(),
+		}, permitTypes, nil)
+		separator, err := TypedDataDomainSeparator(td)
+		if err != nil {
+			return nil, err
+		}
+		return method.Outputs.Pack([32]byte(separator))
+	}
+	return nil, fmt.Errorf("execution reverted")
+}
+
+func TestBuildPermit(t *testing.T) {
+	token, spender := common.HexToAddress("0xe1"), common.HexToAddress("0xa2").String()
+	value, deadline := big.NewInt(100), big.NewInt(1700000000)
+	tests := []struct {
+		name    string
+		node    *fakePermitToken
+		version string
+	}{
+		{"version", &fakePermitToken{token: token, version: "2", domainVersion: "2"}, "2"},
+		{"version without domain separator", &fakePermitToken{token: token, version: "2"}, "2"},
+		// e.g. Uniswap V2 pairs have no version() but a DOMAIN_SEPARATOR() of version 1
+		{"assumed version", &fakePermitToken{token: token, domainVersion: "1"}, "1"},
+		{"assumed version of revert with data", &fakePermitToken{token: token, domainVersion: "1", versionErr: &fakeRevertError{data: "0x"}}, "1"},
+		{"assumed version of missing version", &fakePermitToken{token: token, domainVersion: "1", missing: true}, "1"},
+	}
+	for _, tt := range tests {
+		m := newTestClient(t, "eth", tt.node)
+		td, err := m.BuildPermit(context.Background(), token.String(), testAddress, spender, value, deadline)
+		if err != nil {
+			t.Fatalf("%s: build permit error %s", tt.name, err)
+		}
+		if td.Domain.Version != tt.version || td.Domain.Name != "Token" || td.Message["nonce"].(*big.Int).Int64() != 3 {
+			t.Errorf("%s: permit domain %+v message %v", tt.name, td.Domain, td.Message)
+		}
+	}
+
+	errTests := []struct {
+		name  string
+		node  *fakePermitToken
+		want  string
+		value *big.Int
+	}{
+		{"unchecked assumed version", &fakePermitToken{token: token}, "assumed version [1]", value},
+		{"assumed version mismatch", &fakePermitToken{token: token, domainVersion: "2"}, "assumed version [1]", value},
+		{"version mismatch", &fakePermitToken{token: token, version: "1", domainVersion: "2"}, "version [1]", value},
+		// only a revert falls back to the assumed version
+		{"version error", &fakePermitToken{token: token, domainVersion: "1", versionErr: fmt.Errorf("header not found")}, "header not found", value},
+		{"nil value", &fakePermitToken{token: token, version: "1", domainVersion: "1"}, "must not be nil", nil},
+	}
+	for _, tt := range errTests {
+		m := newTestClient(t, "eth", tt.node)
+		if _, err := m.BuildPermit(context.Background(), token.String(), testAddress, spender, tt.value, deadline); err == nil ||
+			!strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: build permit should fail with %q, got error %v", tt.name, tt.want, err)
+		}
+	}
+	m := newTestClient(t, "eth", &fakePermitToken{token: token, version: "1", domainVersion: "1"})
+	if _, err := m.BuildPermit(context.Background(), token.String(), testAddress, spender, value, nil); err == nil {
+		t.Errorf("build permit of nil deadline should fail")
+	}
+}
+
+func TestSignPermit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token, spender := common.HexToAddress("0xe1"), common.HexToAddress("0xa2")
+	m := newTestClient(t, "eth", &fakePermitToken{token: token, version: "1", domainVersion: "1"})
+	permit, err := m.SignPermit(context.Background(), key, token.String(), spender.String(), big.NewInt(100), big.NewInt(1700000000))
+	if err != nil {
+		t.Fatalf("sign permit error %s", err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	if permit.Owner != owner || permit.V < 27 || permit.Nonce.Int64() != 3 {
+		t.Fatalf("signed permit %+v", permit)
+	}
+	td, _ := m.BuildPermit(context.Background(), token.String(), owner.String(), spender.String(), permit.Value, permit.Deadline)
+	if ok, err := VerifyTypedData(td, permit.Signature, owner.String()); err != nil || !ok {
+		t.Fatalf("verify permit signature error %v", err)
+	}
+	data, err := permit.Pack()
+	if err != nil {
+		t.Fatalf("pack permit error %s", err)
+	}
+	values, err := erc20PermitContractABI.Methods["permit"].Inputs.Unpack(data[4:])
+	if err != nil || values[0].(common.Address) != owner || values[4].(uint8) != permit.V || values[5].([32]byte) != permit.R {
+		t.Fatalf("unpack permit calldata %v error %v", values, err)
+	}
+}
@@ -0,0 +1,217 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// AccountProof is the account and storage Merkle-Patricia proofs returned by eth_getProof
+type AccountProof struct {
+	Address      common.Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     common.Hash
+	Nonce        uint64
+	StorageHash  common.Hash
+	StorageProof []*StorageProof
+}
+
+// StorageProof is the proof of a storage slot
+type StorageProof struct {
+	Key   common.Hash
+	Value *big.Int
+	Proof [][]byte
+}
+
+// GetProof returns the account and storage proofs of address for the storage keys at block number
+func (m *EthereumClient) GetProof(ctx context.Context, strAddress string, keys []string, number uint64) (*AccountProof, error) {
+	type storageResult struct {
+		Key   string          `json:"key"`
+		Value *hexutil.Big    `json:"value"`
+		Proof []hexutil.Bytes `json:"proof"`
+	}
+	type accountResult struct {
+		Address      common.Address  `json:"address"`
+		AccountProof []hexutil.Bytes `json:"accountProof"`
+		Balance      *hexutil.Big    `json:"balance"`
+		CodeHash     common.Hash     `json:"codeHash"`
+		Nonce        hexutil.Uint64  `json:"nonce"`
+		StorageHash  common.Hash     `json:"storageHash"`
+		StorageProof []storageResult `json:"storageProof"`
+	}
+	hashes := make([]common.Hash, len(keys))
+	for i, key := range keys {
+		hashes[i] = Hex2Hash(key)
+	}
+	var res accountResult
+	err := m.ethcli.Client().CallContext(ctx, &res, "eth_getProof", Hex2Address(strAddress), hashes, toBlockNumArg(Uint642Big(number)))
+	if err != nil {
+		return nil, fmt.Errorf("get proof of [%s] error [%s]", strAddress, err)
+	}
+	proof := &AccountProof{
+		Address:      res.Address,
+		AccountProof: toBytesList(res.AccountProof),
+		Balance:      res.Balance.ToInt(),
+		CodeHash:     res.CodeHash,
+		Nonce:        uint64(res.Nonce),
+		StorageHash:  res.StorageHash,
+	}
+	for _, sp := range res.StorageProof {
+		proof.StorageProof = append(proof.StorageProof, &StorageProof{
+			Key:   Hex2Hash(sp.Key),
+			Value: sp.Value.ToInt(),
+			Proof: toBytesList(sp.Proof),
+		})
+	}
+	return proof, nil
+}
+
+// GetVerifiedProof returns the account and storage proofs of address at block number verified against
+// the state root of the block header
+func (m *EthereumClient) GetVerifiedProof(ctx context.Context, strAddress string, keys []string, number uint64) (*AccountProof, error) {
+	header, err := m.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("get header by number [%d] error [%s]", number, err)
+	}
+	var proof *AccountProof
+	proof, err = m.GetProof(ctx, strAddress, keys, number)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(keys))
+	for i, key := range keys {
+		hashes[i] = Hex2Hash(key)
+	}
+	// the node may prove another account or slot than requested
+	if err = proof.Match(Hex2Address(strAddress), hashes); err != nil {
+		return nil, err
+	}
+	if err = proof.Verify(header.Root); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Match check the proof is of the requested address and storage keys in order, Verify only checks the proof is
+// valid for the address and keys it contains
+func (p *AccountProof) Match(address common.Address, keys []common.Hash) error {
+	if p.Address != address {
+		return fmt.Errorf("proof of account [%s] mismatch with requested [%s]", p.Address, address)
+	}
+	if len(p.StorageProof) != len(keys) {
+		return fmt.Errorf("proof has %d storage proofs but %d keys requested", len(p.StorageProof), len(keys))
+	}
+	for i, sp := range p.StorageProof {
+		if sp.Key != keys[i] {
+			return fmt.Errorf("storage proof %d of key [%s] mismatch with requested [%s]", i, sp.Key.Hex(), keys[i].Hex())
+		}
+	}
+	return nil
+}
+
+// Verify check the account proof against state root and the storage proofs against the account storage hash
+func (p *AccountProof) Verify(stateRoot common.Hash) error {
+	if err := VerifyAccountProof(stateRoot, p); err != nil {
+		return err
+	}
+	for _, sp := range p.StorageProof {
+		if err := VerifyStorageProof(p.StorageHash, sp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyAccountProof check the balance, nonce, code hash and storage hash of the proof against state root
+func VerifyAccountProof(stateRoot common.Hash, p *AccountProof) error {
+	value, err := verifyProof(stateRoot, crypto.Keccak256(p.Address.Bytes()), p.AccountProof)
+	if err != nil {
+		return fmt.Errorf("verify account proof of [%s] error [%s]", p.Address, err)
+	}
+	account := types.StateAccount{
+		Balance:  new(big.Int),
+		Root:     types.EmptyRootHash,
+		CodeHash: types.EmptyCodeHash.Bytes(),
+	}
+	if value != nil {
+		if err = rlp.DecodeBytes(value, &account); err != nil {
+			return fmt.Errorf("decode account of [%s] error [%s]", p.Address, err)
+		}
+	}
+	balance := p.Balance
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	// a non-existent account proves the empty values, nodes report a zero code/storage hash for it
+	codeHash, storageHash := p.CodeHash, p.StorageHash
+	if value == nil && codeHash == (common.Hash{}) {
+		codeHash = types.EmptyCodeHash
+	}
+	if value == nil && storageHash == (common.Hash{}) {
+		storageHash = types.EmptyRootHash
+	}
+	switch {
+	case account.Nonce != p.Nonce:
+		return fmt.Errorf("account [%s] nonce %d mismatch with proof %d", p.Address, p.Nonce, account.Nonce)
+	case account.Balance.Cmp(balance) != 0:
+		return fmt.Errorf("account [%s] balance %s mismatch with proof %s", p.Address, balance, account.Balance)
+	case !bytes.Equal(account.CodeHash, codeHash.Bytes()):
+		return fmt.Errorf("account [%s] code hash %s mismatch with proof %x", p.Address, codeHash.Hex(), account.CodeHash)
+	case account.Root != storageHash:
+		return fmt.Errorf("account [%s] storage hash %s mismatch with proof %s", p.Address, storageHash.Hex(), account.Root.Hex())
+	}
+	return nil
+}
+
+// VerifyStorageProof check the value of the storage proof against storage root
+func VerifyStorageProof(storageRoot common.Hash, sp *StorageProof) error {
+	value, err := verifyProof(storageRoot, crypto.Keccak256(sp.Key.Bytes()), sp.Proof)
+	if err != nil {
+		return fmt.Errorf("verify storage proof of key [%s] error [%s]", sp.Key.Hex(), err)
+	}
+	proved := new(big.Int)
+	if value != nil {
+		var content []byte
+		if err = rlp.DecodeBytes(value, &content); err != nil {
+			return fmt.Errorf("decode storage value of key [%s] error [%s]", sp.Key.Hex(), err)
+		}
+		proved.SetBytes(content)
+	}
+	expected := sp.Value
+	if expected == nil {
+		expected = new(big.Int)
+	}
+	if proved.Cmp(expected) != 0 {
+		return fmt.Errorf("storage key [%s] value %s mismatch with proof %s", sp.Key.Hex(), expected, proved)
+	}
+	return nil
+}
+
+// verifyProof returns the value of key proved by the proof nodes against root, nil if the key is proved absent
+func verifyProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	db := memorydb.New()
+	for _, node := range proof {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	return trie.VerifyProof(root, key, db)
+}
+
+func toBytesList(list []hexutil.Bytes) [][]byte {
+	out := make([][]byte, len(list))
+	for i, b := range list {
+		out[i] = b
+	}
+	return out
+}
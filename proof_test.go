@@ -0,0 +1,163 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// testStateTrie returns a state trie of accounts with the first account holding storage slot 1 = 42
+func testStateTrie(t *testing.T, addrs ...common.Address) (state *trie.Trie, storage *trie.Trie, accounts []*types.StateAccount) {
+	storage = trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	slotValue, _ := rlp.EncodeToBytes(big.NewInt(42).Bytes())
+	if err := storage.Update(crypto.Keccak256(common.BigToHash(big.NewInt(1)).Bytes()), slotValue); err != nil {
+		t.Fatalf("update storage trie error %s", err)
+	}
+	state = trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i, addr := range addrs {
+		account := &types.StateAccount{
+			Nonce:    uint64(7 + i),
+			Balance:  big.NewInt(int64(1000 + i)),
+			Root:     types.EmptyRootHash,
+			CodeHash: types.EmptyCodeHash.Bytes(),
+		}
+		if i == 0 {
+			account.Root = storage.Hash()
+		}
+		value, _ := rlp.EncodeToBytes(account)
+		if err := state.Update(crypto.Keccak256(addr.Bytes()), value); err != nil {
+			t.Fatalf("update state trie error %s", err)
+		}
+		accounts = append(accounts, account)
+	}
+	return state, storage, accounts
+}
+
+// testAccountProof prove the account of state trie and the slots of storage trie
+func testAccountProof(t *testing.T, state, storage *trie.Trie, addr common.Address, account *types.StateAccount, keys ...common.Hash) *AccountProof {
+	var nodes proofList
+	if err := state.Prove(crypto.Keccak256(addr.Bytes()), 0, &nodes); err != nil {
+		t.Fatalf("prove account error %s", err)
+	}
+	proof := &AccountProof{
+		Address:      addr,
+		AccountProof: nodes,
+		Balance:      account.Balance,
+		CodeHash:     common.BytesToHash(account.CodeHash),
+		Nonce:        account.Nonce,
+		StorageHash:  account.Root,
+	}
+	for _, key := range keys {
+		var slotNodes proofList
+		if err := storage.Prove(crypto.Keccak256(key.Bytes()), 0, &slotNodes); err != nil {
+			t.Fatalf("prove storage error %s", err)
+		}
+		value := new(big.Int)
+		if key == common.BigToHash(big.NewInt(1)) {
+			value.SetInt64(42)
+		}
+		proof.StorageProof = append(proof.StorageProof, &StorageProof{Key: key, Value: value, Proof: slotNodes})
+	}
+	return proof
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	addr := Hex2Address(testAddress)
+	state, storage, accounts := testStateTrie(t, addr, common.HexToAddress("0x01"))
+	slot1, slot2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	proof := testAccountProof(t, state, storage, addr, accounts[0], slot1, slot2)
+	if err := proof.Verify(state.Hash()); err != nil {
+		t.Fatalf("verify account proof error %s", err)
+	}
+	if err := proof.Match(addr, []common.Hash{slot1, slot2}); err != nil {
+		t.Fatalf("match account proof error %s", err)
+	}
+	proof.StorageProof[0].Value = big.NewInt(43)
+	if err := proof.Verify(state.Hash()); err == nil {
+		t.Fatalf("verify storage proof with tampered value should fail")
+	}
+	proof.StorageProof[0].Value = big.NewInt(42)
+	proof.Balance = big.NewInt(1001)
+	if err := proof.Verify(state.Hash()); err == nil {
+		t.Fatalf("verify account proof with tampered balance should fail")
+	}
+	if err := proof.Verify(common.Hash{1}); err == nil {
+		t.Fatalf("verify account proof with other root should fail")
+	}
+}
+
+func TestAccountProofMatch(t *testing.T) {
+	addr, other := Hex2Address(testAddress), common.HexToAddress("0x01")
+	state, storage, accounts := testStateTrie(t, addr, other)
+	slot1, slot2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	// valid proofs of another account or other slots than requested
+	tests := []struct {
+		name  string
+		proof *AccountProof
+		keys  []common.Hash
+	}{
+		{"other address", testAccountProof(t, state, storage, other, accounts[1]), nil},
+		{"other key", testAccountProof(t, state, storage, addr, accounts[0], slot2), []common.Hash{slot1}},
+		{"keys out of order", testAccountProof(t, state, storage, addr, accounts[0], slot2, slot1), []common.Hash{slot1, slot2}},
+		{"missing key", testAccountProof(t, state, storage, addr, accounts[0], slot1), []common.Hash{slot1, slot2}},
+		{"extra key", testAccountProof(t, state, storage, addr, accounts[0], slot1, slot2), []common.Hash{slot1}},
+	}
+	for _, tt := range tests {
+		if err := tt.proof.Verify(state.Hash()); err != nil {
+			t.Fatalf("%s: verify proof error %s", tt.name, err)
+		}
+		if err := tt.proof.Match(addr, tt.keys); err == nil {
+			t.Errorf("%s: match should fail", tt.name)
+		}
+	}
+}
+
+// fakeProofNode serve a header of state root and a proof of another account than requested
+type fakeProofNode struct {
+	header *types.Header
+	proof  *AccountProof
+}
+
+func (f *fakeProofNode) GetBlockByNumber(number string, full bool) *types.Header {
+	return f.header
+}
+
+func (f *fakeProofNode) GetProof(addr common.Address, keys []common.Hash, number string) map[string]interface{} {
+	nodes := make([]hexutil.Bytes, len(f.proof.AccountProof))
+	for i, node := range f.proof.AccountProof {
+		nodes[i] = node
+	}
+	return map[string]interface{}{
+		"address":      f.proof.Address,
+		"accountProof": nodes,
+		"balance":      (*hexutil.Big)(f.proof.Balance),
+		"codeHash":     f.proof.CodeHash,
+		"nonce":        hexutil.Uint64(f.proof.Nonce),
+		"storageHash":  f.proof.StorageHash,
+		"storageProof": []interface{}{},
+	}
+}
+
+func TestGetVerifiedProofOtherAccount(t *testing.T) {
+	addr, other := Hex2Address(testAddress), common.HexToAddress("0x01")
+	state, storage, accounts := testStateTrie(t, addr, other)
+	header := &types.Header{Number: big.NewInt(1), Root: state.Hash(), Difficulty: new(big.Int)}
+	m := newTestClient(t, "eth", &fakeProofNode{header: header, proof: testAccountProof(t, state, storage, other, accounts[1])})
+	if _, err := m.GetVerifiedProof(context.Background(), testAddress, nil, 1); err == nil ||
+		!strings.Contains(err.Error(), "mismatch with requested") {
+		t.Fatalf("verified proof of other account should fail by mismatch, got error %v", err)
+	}
+	m = newTestClient(t, "eth", &fakeProofNode{header: header, proof: testAccountProof(t, state, storage, addr, accounts[0])})
+	if _, err := m.GetVerifiedProof(context.Background(), testAddress, nil, 1); err != nil {
+		t.Fatalf("verified proof error %s", err)
+	}
+}
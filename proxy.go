@@ -0,0 +1,141 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	ProxyTypeTransparent = "Transparent" // EIP-1967 proxy with an admin
+	ProxyTypeUUPS        = "UUPS"        // EIP-1967 proxy upgraded by the implementation (EIP-1822 proxiableUUID)
+	ProxyTypeEIP1967     = "EIP1967"     // EIP-1967 proxy of neither transparent nor UUPS pattern
+	ProxyTypeBeacon      = "Beacon"      // EIP-1967 beacon proxy
+	ProxyTypeMinimal     = "EIP1167"     // EIP-1167 minimal proxy (clone)
+)
+
+const proxyABI = `[
+{"inputs":[],"name":"implementation","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"proxiableUUID","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}
+]`
+
+var proxyContractABI = mustLoadABI(proxyABI)
+
+var (
+	// EIP-1967 storage slots, keccak256(label) - 1
+	eip1967ImplementationSlot = eip1967Slot("eip1967.proxy.implementation")
+	eip1967AdminSlot          = eip1967Slot("eip1967.proxy.admin")
+	eip1967BeaconSlot         = eip1967Slot("eip1967.proxy.beacon")
+
+	// EIP-1167 runtime code is minimalProxyPrefix || implementation address || minimalProxySuffix
+	minimalProxyPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// ProxyInfo is the proxy pattern and the addresses behind a proxy contract
+type ProxyInfo struct {
+	Type           string
+	Proxy          common.Address
+	Implementation common.Address
+	Admin          common.Address // transparent proxy only
+	Beacon         common.Address // beacon proxy only
+}
+
+func eip1967Slot(label string) common.Hash {
+	slot := new(big.Int).SetBytes(crypto.Keccak256([]byte(label)))
+	return common.BigToHash(slot.Sub(slot, big.NewInt(1)))
+}
+
+// DetectProxy detect the proxy pattern of contract at latest block by the EIP-1967 storage slots and the EIP-1167
+// runtime code, returns nil if the contract is not a proxy
+func (m *EthereumClient) DetectProxy(ctx context.Context, strAddress string) (info *ProxyInfo, err error) {
+	proxy := Hex2Address(strAddress)
+	var code []byte
+	code, err = m.ethcli.CodeAt(ctx, proxy, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get code of [%s] error [%s]", strAddress, err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract code at [%s]", strAddress)
+	}
+	if len(code) == len(minimalProxyPrefix)+common.AddressLength+len(minimalProxySuffix) &&
+		bytes.HasPrefix(code, minimalProxyPrefix) && bytes.HasSuffix(code, minimalProxySuffix) {
+		return &ProxyInfo{
+			Type:           ProxyTypeMinimal,
+			Proxy:          proxy,
+			Implementation: common.BytesToAddress(code[len(minimalProxyPrefix) : len(minimalProxyPrefix)+common.AddressLength]),
+		}, nil
+	}
+
+	var impl common.Address
+	if impl, err = m.slotAddress(ctx, proxy, eip1967ImplementationSlot); err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		info = &ProxyInfo{Type: ProxyTypeEIP1967, Proxy: proxy, Implementation: impl}
+		if info.Admin, err = m.slotAddress(ctx, proxy, eip1967AdminSlot); err != nil {
+			return nil, err
+		}
+		if info.Admin != (common.Address{}) {
+			info.Type = ProxyTypeTransparent
+		} else if m.isProxiable(ctx, impl) {
+			info.Type = ProxyTypeUUPS
+		}
+		return info, nil
+	}
+
+	var beacon common.Address
+	if beacon, err = m.slotAddress(ctx, proxy, eip1967BeaconSlot); err != nil {
+		return nil, err
+	}
+	if beacon != (common.Address{}) {
+		var values []interface{}
+		values, err = m.callView(ctx, proxyContractABI, beacon.String(), "implementation")
+		if err != nil {
+			return nil, fmt.Errorf("get implementation of beacon [%s] error [%s]", beacon, err)
+		}
+		return &ProxyInfo{
+			Type:           ProxyTypeBeacon,
+			Proxy:          proxy,
+			Implementation: values[0].(common.Address),
+			Beacon:         beacon,
+		}, nil
+	}
+	return nil, nil
+}
+
+// ResolveProxy detect the proxy pattern of contract and register the proxy to its implementation in the ABI
+// registry, so that the calls and events of proxy are decoded by the ABI registered to the implementation.
+// Returns nil if the contract is not a proxy
+func (m *EthereumClient) ResolveProxy(ctx context.Context, strAddress string) (info *ProxyInfo, err error) {
+	if info, err = m.DetectProxy(ctx, strAddress); err != nil || info == nil {
+		return info, err
+	}
+	if info.Implementation == (common.Address{}) {
+		return nil, fmt.Errorf("%s proxy [%s] has no implementation", info.Type, strAddress)
+	}
+	m.Registry.RegisterProxy(info.Proxy.String(), info.Implementation.String())
+	return info, nil
+}
+
+// slotAddress read the address stored in the lower 20 bytes of storage slot at latest block
+func (m *EthereumClient) slotAddress(ctx context.Context, contract common.Address, slot common.Hash) (common.Address, error) {
+	value, err := m.ethcli.StorageAt(ctx, contract, slot, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("get storage [%s] of [%s] error [%s]", slot, contract, err)
+	}
+	return common.BytesToAddress(value), nil
+}
+
+// isProxiable reports whether the implementation returns the EIP-1967 implementation slot by EIP-1822 proxiableUUID()
+func (m *EthereumClient) isProxiable(ctx context.Context, impl common.Address) bool {
+	values, err := m.callView(ctx, proxyContractABI, impl.String(), "proxiableUUID")
+	if err != nil {
+		return false
+	}
+	return common.Hash(values[0].([32]byte)) == eip1967ImplementationSlot
+}
@@ -0,0 +1,92 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeProxyNode serve the code and storage of contracts, implementation() of beacons and proxiableUUID() of UUPS
+// implementations
+type fakeProxyNode struct {
+	code    map[common.Address][]byte
+	storage map[common.Address]map[common.Hash]common.Hash
+	beacons map[common.Address]common.Address // beacon to implementation
+	uups    map[common.Address]bool
+}
+
+func (f *fakeProxyNode) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return f.code[addr]
+}
+
+func (f *fakeProxyNode) GetStorageAt(addr common.Address, key common.Hash, block string) hexutil.Bytes {
+	value := f.storage[addr][key]
+	return value.Bytes()
+}
+
+func (f *fakeProxyNode) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	method, err := proxyContractABI.MethodById(args.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case method.Name == "implementation":
+		if impl, ok := f.beacons[args.To]; ok {
+			return method.Outputs.Pack(impl)
+		}
+	case method.Name == "proxiableUUID" && f.uups[args.To]:
+		return method.Outputs.Pack([32]byte(eip1967ImplementationSlot))
+	}
+	return nil, fmt.Errorf("execution reverted")
+}
+
+func TestDetectProxy(t *testing.T) {
+	proxy, impl := common.HexToAddress("0xa1"), common.HexToAddress("0xb1")
+	admin, beacon := common.HexToAddress("0xc1"), common.HexToAddress("0xd1")
+	runtime := []byte{0x60, 0x80}
+	minimal := append(append(common.CopyBytes(minimalProxyPrefix), impl.Bytes()...), minimalProxySuffix...)
+	tests := []struct {
+		name    string
+		code    []byte
+		storage map[common.Hash]common.Hash
+		uups    bool
+		want    *ProxyInfo
+	}{
+		{"minimal", minimal, nil, false, &ProxyInfo{Type: ProxyTypeMinimal, Proxy: proxy, Implementation: impl}},
+		{"transparent", runtime, map[common.Hash]common.Hash{eip1967ImplementationSlot: impl.Hash(), eip1967AdminSlot: admin.Hash()}, false,
+			&ProxyInfo{Type: ProxyTypeTransparent, Proxy: proxy, Implementation: impl, Admin: admin}},
+		{"uups", runtime, map[common.Hash]common.Hash{eip1967ImplementationSlot: impl.Hash()}, true,
+			&ProxyInfo{Type: ProxyTypeUUPS, Proxy: proxy, Implementation: impl}},
+		{"eip1967", runtime, map[common.Hash]common.Hash{eip1967ImplementationSlot: impl.Hash()}, false,
+			&ProxyInfo{Type: ProxyTypeEIP1967, Proxy: proxy, Implementation: impl}},
+		{"beacon", runtime, map[common.Hash]common.Hash{eip1967BeaconSlot: beacon.Hash()}, false,
+			&ProxyInfo{Type: ProxyTypeBeacon, Proxy: proxy, Implementation: impl, Beacon: beacon}},
+		{"not proxy", runtime, nil, false, nil},
+		// the minimal proxy code must be of the exact length
+		{"minimal prefix", append(common.CopyBytes(minimal), 0x00), nil, false, nil},
+	}
+	for _, tt := range tests {
+		node := &fakeProxyNode{
+			code:    map[common.Address][]byte{proxy: tt.code, impl: runtime, beacon: runtime},
+			storage: map[common.Address]map[common.Hash]common.Hash{proxy: tt.storage},
+			beacons: map[common.Address]common.Address{beacon: impl},
+			uups:    map[common.Address]bool{impl: tt.uups},
+		}
+		m := newTestClient(t, "eth", node)
+		info, err := m.DetectProxy(context.Background(), proxy.String())
+		if err != nil {
+			t.Fatalf("%s: detect proxy error %s", tt.name, err)
+		}
+		if (info == nil) != (tt.want == nil) || (info != nil && *info != *tt.want) {
+			t.Errorf("%s: detect proxy got %+v, want %+v", tt.name, info, tt.want)
+		}
+	}
+
+	m := newTestClient(t, "eth", &fakeProxyNode{})
+	if _, err := m.DetectProxy(context.Background(), proxy.String()); err == nil {
+		t.Errorf("detect proxy of address without code should fail")
+	}
+}
@@ -0,0 +1,243 @@
+package ethclient
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// BlobTxType is the EIP-4844 blob transaction type, not supported by types.Transaction of go-ethereum v1.12
+	BlobTxType = 0x03
+	// blobGasPerBlob is the gas consumed by a blob, 2^17
+	blobGasPerBlob = 1 << 17
+)
+
+// RawTransaction is the fields of a raw signed transaction decoded offline
+type RawTransaction struct {
+	Type       uint8
+	Hash       common.Hash
+	ChainID    *big.Int // nil for legacy transactions without EIP-155 replay protection
+	Nonce      uint64
+	From       common.Address
+	To         *common.Address // nil for contract creation
+	Value      *big.Int
+	Gas        uint64
+	GasPrice   *big.Int // legacy and access list transactions
+	GasTipCap  *big.Int // dynamic fee and blob transactions
+	GasFeeCap  *big.Int // dynamic fee and blob transactions
+	BlobFeeCap *big.Int // blob transactions
+	BlobHashes []common.Hash
+	Data       []byte
+	AccessList types.AccessList
+	V, R, S    *big.Int
+	Sidecar    *BlobSidecar       // blobs of the network wrapper form of blob transaction
+	Tx         *types.Transaction // nil for blob transactions
+}
+
+// BlobSidecar is the blobs, KZG commitments and proofs of a blob transaction network wrapper
+type BlobSidecar struct {
+	Blobs       [][]byte
+	Commitments [][]byte
+	Proofs      [][]byte
+}
+
+// blobTx is the RLP payload of a blob transaction
+type blobTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
+	BlobFeeCap *big.Int
+	BlobHashes []common.Hash
+	V, R, S    *big.Int
+}
+
+// blobTxWithBlobs is the network wrapper of a blob transaction
+type blobTxWithBlobs struct {
+	Tx          blobTx
+	Blobs       [][]byte
+	Commitments [][]byte
+	Proofs      [][]byte
+}
+
+// DecodeRawTransaction decode a raw signed transaction hex of legacy, access list (EIP-2930), dynamic fee (EIP-1559)
+// or blob (EIP-4844, canonical or network wrapper form) type and recover its sender, no node is needed
+func DecodeRawTransaction(strRawTx string) (*RawTransaction, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(strRawTx))
+	if err != nil {
+		return nil, fmt.Errorf("decode raw tx hex error [%s]", err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty raw tx")
+	}
+	if raw[0] == BlobTxType {
+		return decodeBlobTransaction(raw)
+	}
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("decode raw tx error [%s]", err)
+	}
+	var chainId *big.Int
+	if tx.Type() != types.LegacyTxType || tx.Protected() {
+		chainId = tx.ChainId()
+	}
+	from, err := types.LatestSignerForChainID(chainId).Sender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover raw tx sender error [%s]", err)
+	}
+	v, r, s := tx.RawSignatureValues()
+	rawTx := &RawTransaction{
+		Type:       tx.Type(),
+		Hash:       tx.Hash(),
+		ChainID:    chainId,
+		Nonce:      tx.Nonce(),
+		From:       from,
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Gas:        tx.Gas(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+		V:          v,
+		R:          r,
+		S:          s,
+		Tx:         tx,
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		rawTx.GasTipCap, rawTx.GasFeeCap = tx.GasTipCap(), tx.GasFeeCap()
+	} else {
+		rawTx.GasPrice = tx.GasPrice()
+	}
+	return rawTx, nil
+}
+
+// decodeBlobTransaction decode 0x03 || rlp(payload) or 0x03 || rlp([payload, blobs, commitments, proofs])
+func decodeBlobTransaction(raw []byte) (*RawTransaction, error) {
+	kind, content, _, err := rlp.Split(raw[1:])
+	if err != nil || kind != rlp.List {
+		return nil, fmt.Errorf("decode blob tx error: not a RLP list")
+	}
+	var tx blobTx
+	var sidecar *BlobSidecar
+	// the tx hash is over the payload, which is the first element of the network wrapper
+	payload := raw[1:]
+	if kind, _, rest, err := rlp.Split(content); err == nil && kind == rlp.List {
+		payload = content[:len(content)-len(rest)]
+		var wrapper blobTxWithBlobs
+		if err = rlp.DecodeBytes(raw[1:], &wrapper); err != nil {
+			return nil, fmt.Errorf("decode blob tx network wrapper error [%s]", err)
+		}
+		tx = wrapper.Tx
+		sidecar = &BlobSidecar{Blobs: wrapper.Blobs, Commitments: wrapper.Commitments, Proofs: wrapper.Proofs}
+		if len(sidecar.Blobs) != len(tx.BlobHashes) || len(sidecar.Commitments) != len(tx.BlobHashes) ||
+			len(sidecar.Proofs) != len(tx.BlobHashes) {
+			return nil, fmt.Errorf("blob tx has %d blob hashes but %d blobs, %d commitments and %d proofs",
+				len(tx.BlobHashes), len(sidecar.Blobs), len(sidecar.Commitments), len(sidecar.Proofs))
+		}
+		for i, commitment := range sidecar.Commitments {
+			if hash := kzgToVersionedHash(commitment); hash != tx.BlobHashes[i] {
+				return nil, fmt.Errorf("blob %d commitment versioned hash %s mismatch with %s", i, hash.Hex(), tx.BlobHashes[i].Hex())
+			}
+		}
+	} else if err = rlp.DecodeBytes(payload, &tx); err != nil {
+		return nil, fmt.Errorf("decode blob tx error [%s]", err)
+	}
+	if tx.V.Cmp(big.NewInt(1)) > 0 || !crypto.ValidateSignatureValues(byte(tx.V.Uint64()), tx.R, tx.S, true) {
+		return nil, fmt.Errorf("blob tx signature values invalid")
+	}
+	unsigned, err := rlp.EncodeToBytes([]interface{}{
+		tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.To, tx.Value, tx.Data, tx.AccessList,
+		tx.BlobFeeCap, tx.BlobHashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	tx.R.FillBytes(sig[:32])
+	tx.S.FillBytes(sig[32:64])
+	sig[64] = byte(tx.V.Uint64())
+	from, err := RecoverHash(crypto.Keccak256(append([]byte{BlobTxType}, unsigned...)), sig)
+	if err != nil {
+		return nil, fmt.Errorf("recover blob tx sender error [%s]", err)
+	}
+	to := tx.To
+	return &RawTransaction{
+		Type:       BlobTxType,
+		Hash:       crypto.Keccak256Hash(raw[:1], payload),
+		ChainID:    tx.ChainID,
+		Nonce:      tx.Nonce,
+		From:       from,
+		To:         &to,
+		Value:      tx.Value,
+		Gas:        tx.Gas,
+		GasTipCap:  tx.GasTipCap,
+		GasFeeCap:  tx.GasFeeCap,
+		BlobFeeCap: tx.BlobFeeCap,
+		BlobHashes: tx.BlobHashes,
+		Data:       tx.Data,
+		AccessList: tx.AccessList,
+		V:          tx.V,
+		R:          tx.R,
+		S:          tx.S,
+		Sidecar:    sidecar,
+	}, nil
+}
+
+// kzgToVersionedHash returns the versioned hash 0x01 || sha256(commitment)[1:] of a KZG commitment
+func kzgToVersionedHash(commitment []byte) common.Hash {
+	hash := common.Hash(sha256.Sum256(commitment))
+	hash[0] = 0x01
+	return hash
+}
+
+// DecodeCalldata decode the calldata of transaction by the contract ABI
+func (m *RawTransaction) DecodeCalldata(contractABI abi.ABI) (*CallMethod, error) {
+	if len(m.Data) < 4 {
+		return nil, fmt.Errorf("tx calldata too short to have a method id")
+	}
+	method, err := contractABI.MethodById(m.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if _, err = method.Inputs.Unpack(m.Data[4:]); err != nil {
+		return nil, fmt.Errorf("unpack method [%s] inputs error [%s]", method.Name, err)
+	}
+	return &CallMethod{
+		Method: method,
+		ABI:    contractABI,
+		Data:   m.Data[4:],
+	}, nil
+}
+
+// IsContractCreation reports whether the transaction creates a contract
+func (m *RawTransaction) IsContractCreation() bool {
+	return m.To == nil
+}
+
+// Cost returns the maximum wei the sender pays: value + gas * (gas price or max fee) + blob gas * max blob fee
+func (m *RawTransaction) Cost() *big.Int {
+	price := m.GasPrice
+	if price == nil {
+		price = m.GasFeeCap
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(m.Gas), price)
+	cost.Add(cost, m.Value)
+	if m.BlobFeeCap != nil {
+		blobGas := new(big.Int).SetUint64(uint64(len(m.BlobHashes)) * blobGasPerBlob)
+		cost.Add(cost, blobGas.Mul(blobGas, m.BlobFeeCap))
+	}
+	return cost
+}
@@ -0,0 +1,136 @@
+package ethclient
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// raw transactions signed by the private key 0x4646...46 of the EIP-155 example, the legacy EIP-155 one is the
+// example of the EIP
+const (
+	rawTxSender       = "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F"
+	rawTxHomestead    = "0xf85180843b9aca0082cf0880808260001ca05c2dcbbe742b56115fa850913afcbc8a0abdc1a29de55068aa2e83f9abf61670a072e1afb0f260a95f3f8ed3a11728fa86f21738944d83d4fd962ca8c9c3aec77c"
+	rawTxEIP155       = "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	rawTxAccessList   = "0x01f8a301018506fc23ac008275309435353535353535353535353535353535353535350184a9059cbbf838f7943535353535353535353535353535353535353535e1a0000000000000000000000000000000000000000000000000000000000000000180a07f64988d18cee8f9c59a16d78ad8efbb4db764dd3eeb1873fd362a2b558d36a3a054c20b3d0fd55e37ea492eee870ed151fab15d9da5a6a7494e22213723e0f40d"
+	rawTxDynamicFee   = "0x02f89201028477359400850ba43b740082ea608080826000f838f7943535353535353535353535353535353535353535e1a0000000000000000000000000000000000000000000000000000000000000000180a045e6778ec00c018fd337faa1f6a291cfe426e868946ca2d0aada8497ddd9585aa038079bf05f2589cc1d99826788158468bd0d3425f5b7644b51b6bce411fcf691"
+	rawTxBlobPayload  = "f8cb0103843b9aca008509502f90008252089435353535353535353535353535353535353535350580f838f7943535353535353535353535353535353535353535e1a0000000000000000000000000000000000000000000000000000000000000000184b2d05e00e1a0010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c44401480a0d73cdc860808356e556dc4a8e425fedc3b751d60e4ac11eda47aa74bc960f708a0315ba41452990f90ff2aad546590aa8e6d11bc53b296e2b51b785152367a1428"
+	rawTxBlob         = "0x03" + rawTxBlobPayload
+	rawTxBlobWithBlob = "0x03f90137" + rawTxBlobPayload + "c58401020304" +
+		"f1b0c00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000" +
+		"f1b0c10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+)
+
+func TestDecodeRawTransaction(t *testing.T) {
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{common.BigToHash(big.NewInt(1))}}}
+	blobHash := common.HexToHash("0x010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c444014")
+	tests := []struct {
+		name    string
+		raw     string
+		typ     uint8
+		hash    string
+		chainId *big.Int
+		nonce   uint64
+		to      *common.Address
+		fees    [4]int64 // gas price, tip cap, fee cap and blob fee cap in gwei, 0 if not set
+		cost    string
+	}{
+		{"homestead", rawTxHomestead, types.LegacyTxType, "0xb47230ee6c079995cafb66e0ee810e1331517bf06d9868adb2c8d5527ab0d0e2",
+			nil, 0, nil, [4]int64{1, 0, 0, 0}, "53000000000000"},
+		{"eip155", rawTxEIP155, types.LegacyTxType, "0x33469b22e9f636356c4160a87eb19df52b7412e8eac32a4a55ffe88ea8350788",
+			big.NewInt(1), 9, &to, [4]int64{20, 0, 0, 0}, "1000420000000000000"},
+		{"access list", rawTxAccessList, types.AccessListTxType, "0x9ede84f51444f79954b26f42ba9cbcabb7610d53189a41dcfb5331915670bd3e",
+			big.NewInt(1), 1, &to, [4]int64{30, 0, 0, 0}, "900000000000001"},
+		{"dynamic fee", rawTxDynamicFee, types.DynamicFeeTxType, "0x908599695b4a0d8bcaff5be1ee45fb3a78026dfd160d990d6c8365d0c433d2f6",
+			big.NewInt(1), 2, nil, [4]int64{0, 2, 50, 0}, "3000000000000000"},
+		{"blob", rawTxBlob, BlobTxType, "0x4d80cc76bd2f0ec90c3e18b6b4ef56e4a2201b01faf605469e138cbd1c7ba140",
+			big.NewInt(1), 3, &to, [4]int64{0, 1, 40, 3}, "1233216000000005"},
+		// the hash of the network wrapper is the hash of the canonical form
+		{"blob network wrapper", rawTxBlobWithBlob, BlobTxType, "0x4d80cc76bd2f0ec90c3e18b6b4ef56e4a2201b01faf605469e138cbd1c7ba140",
+			big.NewInt(1), 3, &to, [4]int64{0, 1, 40, 3}, "1233216000000005"},
+	}
+	gwei := func(n int64) string {
+		if n == 0 {
+			return "<nil>"
+		}
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)).String()
+	}
+	for _, tt := range tests {
+		tx, err := DecodeRawTransaction(tt.raw)
+		if err != nil {
+			t.Fatalf("%s: decode raw tx error %s", tt.name, err)
+		}
+		if tx.Type != tt.typ || tx.Hash.Hex() != tt.hash || tx.Nonce != tt.nonce {
+			t.Errorf("%s: decoded type %d hash %s nonce %d", tt.name, tx.Type, tx.Hash.Hex(), tx.Nonce)
+		}
+		if tx.From != common.HexToAddress(rawTxSender) {
+			t.Errorf("%s: recovered sender %s, want %s", tt.name, tx.From.Hex(), rawTxSender)
+		}
+		if (tx.ChainID == nil) != (tt.chainId == nil) || (tx.ChainID != nil && tx.ChainID.Cmp(tt.chainId) != 0) {
+			t.Errorf("%s: chain id %v, want %v", tt.name, tx.ChainID, tt.chainId)
+		}
+		if (tx.To == nil) != (tt.to == nil) || (tx.To != nil && *tx.To != *tt.to) || tx.IsContractCreation() != (tt.to == nil) {
+			t.Errorf("%s: to %v, want %v", tt.name, tx.To, tt.to)
+		}
+		fees := []*big.Int{tx.GasPrice, tx.GasTipCap, tx.GasFeeCap, tx.BlobFeeCap}
+		for i, fee := range fees {
+			if got := fee.String(); got != gwei(tt.fees[i]) {
+				t.Errorf("%s: fee %d is %s, want %s", tt.name, i, got, gwei(tt.fees[i]))
+			}
+		}
+		if cost := tx.Cost().String(); cost != tt.cost {
+			t.Errorf("%s: cost %s, want %s", tt.name, cost, tt.cost)
+		}
+		if tt.typ == BlobTxType {
+			if tx.Tx != nil || len(tx.BlobHashes) != 1 || tx.BlobHashes[0] != blobHash {
+				t.Errorf("%s: blob hashes %v", tt.name, tx.BlobHashes)
+			}
+		} else if tx.Tx == nil || tx.Tx.Hash() != tx.Hash {
+			t.Errorf("%s: go-ethereum transaction not set", tt.name)
+		}
+		if tt.typ != types.LegacyTxType && (len(tx.AccessList) != 1 || tx.AccessList[0].StorageKeys[0] != accessList[0].StorageKeys[0]) {
+			t.Errorf("%s: access list %v", tt.name, tx.AccessList)
+		}
+	}
+
+	canonical, _ := DecodeRawTransaction(rawTxBlob)
+	wrapped, _ := DecodeRawTransaction(rawTxBlobWithBlob)
+	if canonical.Sidecar != nil {
+		t.Errorf("canonical blob tx has sidecar")
+	}
+	if wrapped.Sidecar == nil || len(wrapped.Sidecar.Blobs) != 1 || len(wrapped.Sidecar.Commitments[0]) != 48 ||
+		wrapped.Sidecar.Proofs[0][0] != 0xc1 {
+		t.Errorf("network wrapper sidecar %v", wrapped.Sidecar)
+	}
+	if data, _ := DecodeRawTransaction(rawTxAccessList); len(data.Data) != 4 {
+		t.Errorf("access list tx data %x", data.Data)
+	}
+}
+
+func TestDecodeRawTransactionTampered(t *testing.T) {
+	// the nonce changed from 9 to 8 still recovers, but another sender
+	tx, err := DecodeRawTransaction(strings.Replace(rawTxEIP155, "f86c09", "f86c08", 1))
+	if err != nil {
+		t.Fatalf("decode tampered raw tx error %s", err)
+	}
+	if tx.From == common.HexToAddress(rawTxSender) {
+		t.Errorf("tampered tx recovered the original sender")
+	}
+	for name, raw := range map[string]string{
+		"empty":             "0x",
+		"not hex":           "f86c09",
+		"truncated":         rawTxDynamicFee[:len(rawTxDynamicFee)-2],
+		"blob not list":     "0x0301",
+		"blob truncated":    rawTxBlob[:len(rawTxBlob)-2],
+		"commitment":        strings.Replace(rawTxBlobWithBlob, "f1b0c0", "f1b0c2", 1),
+		"blob signature v2": "0x03" + strings.Replace(rawTxBlobPayload, "01480a0d73c", "01402a0d73c", 1),
+	} {
+		if _, err = DecodeRawTransaction(raw); err == nil {
+			t.Errorf("decode %s raw tx should fail", name)
+		}
+	}
+}
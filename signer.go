@@ -0,0 +1,108 @@
+package ethclient
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs a 32 bytes hash and returns a 65 bytes [R || S || V] signature (V is 0 or 1)
+type Signer interface {
+	Address() common.Address
+	SignHash(hash []byte) ([]byte, error)
+}
+
+type privateKeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+type keyStoreSigner struct {
+	ks      *KeyStore
+	address common.Address
+}
+
+// NewSigner new signer from private key hex string, bytes, *ecdsa.PrivateKey or an existing Signer
+func NewSigner(signer interface{}) (Signer, error) {
+	if s, ok := signer.(Signer); ok {
+		return s, nil
+	}
+	pk, err := NewPrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	return &privateKeySigner{key: pk}, nil
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *privateKeySigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+// Signer returns a signer backed by an unlocked account of key store
+func (m *KeyStore) Signer(strAddress string) (Signer, error) {
+	acc, err := m.find(strAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &keyStoreSigner{ks: m, address: acc.Address}, nil
+}
+
+func (s *keyStoreSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *keyStoreSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.ks.SignHash(s.address.String(), hash)
+}
+
+// signHash sign hash by signer and returns the signature with V in {27, 28}
+func signHash(signer interface{}, hash []byte) (sig []byte, err error) {
+	var s Signer
+	s, err = NewSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+	sig, err = s.SignHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("signature length %d invalid, want %d", len(sig), crypto.SignatureLength)
+	}
+	// external signers (e.g. hardware wallets) may already return V in {27, 28}
+	switch v := sig[crypto.RecoveryIDOffset]; v {
+	case 0, 1:
+		sig[crypto.RecoveryIDOffset] += 27
+	case 27, 28:
+	default:
+		return nil, fmt.Errorf("signature recovery id %d invalid", v)
+	}
+	return sig, nil
+}
+
+// RecoverHash recover the signer address from hash and a 65 bytes signature (V can be 0, 1, 27 or 28)
+func RecoverHash(hash []byte, sig []byte) (addr common.Address, err error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature length %d invalid, want %d", len(sig), crypto.SignatureLength)
+	}
+	rsv := make([]byte, crypto.SignatureLength)
+	copy(rsv, sig)
+	v := rsv[crypto.RecoveryIDOffset]
+	switch v {
+	case 0, 1:
+	case 27, 28:
+		rsv[crypto.RecoveryIDOffset] = v - 27
+	default:
+		return common.Address{}, fmt.Errorf("signature recovery id %d invalid", v)
+	}
+	pub, err := crypto.SigToPub(hash, rsv)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
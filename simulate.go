@@ -0,0 +1,194 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SimulationResult is the preview of a transaction executed on top of the latest block (or pending state, see Simulate)
+type SimulationResult struct {
+	Success        bool
+	ReturnData     []byte
+	Error          string
+	RevertReason   string
+	GasUsed        uint64
+	Logs           []*CallFrameLog  // logs of the succeeded frames, Event is decoded by the ABI registry
+	BalanceChanges []*BalanceChange // net ETH and token balance changes per address (gas fee excluded)
+	Trace          *CallFrame       // call tree, nil if the node does not support debug_traceCall
+}
+
+// BalanceChange is the net balance change of an address, Token is the zero address for ETH and
+// Delta is the number of tokens for ERC721
+type BalanceChange struct {
+	Address common.Address
+	Token   common.Address
+	Delta   *big.Int
+}
+
+// Simulate execute the call message by debug_traceCall on top of the latest block (tracing on top of pending state
+// is not supported by geth), or by eth_call and eth_estimateGas against pending state if the node does not support
+// tracing, and returns the result, decoded revert reason, gas used, logs and balance changes
+func (m *EthereumClient) Simulate(ctx context.Context, msg ethereum.CallMsg) (result *SimulationResult, err error) {
+	frame := &CallFrame{}
+	err = m.ethcli.Client().CallContext(ctx, frame, "debug_traceCall", toCallArg(msg), "latest", map[string]interface{}{
+		"tracer":       callTracer,
+		"tracerConfig": &CallTracerConfig{WithLog: true},
+	})
+	if isNotSupported(err) {
+		return m.simulateByCall(ctx, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("debug_traceCall error [%s]", err)
+	}
+	m.DecodeCallFrame(frame)
+	result = &SimulationResult{
+		Success:      frame.Error == "",
+		ReturnData:   frame.Output,
+		Error:        frame.Error,
+		RevertReason: frame.RevertReason,
+		GasUsed:      uint64(frame.GasUsed),
+		Trace:        frame,
+	}
+	if failed := frame.FailedFrame(); failed != nil && failed.RevertReason != "" {
+		result.RevertReason = failed.RevertReason
+	}
+	if result.Success {
+		result.Logs, result.BalanceChanges = simulationChanges(frame)
+	}
+	return result, nil
+}
+
+// SimulateTransaction simulate a signed transaction, see Simulate
+func (m *EthereumClient) SimulateTransaction(ctx context.Context, tx *types.Transaction) (*SimulationResult, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover tx sender error [%s]", err)
+	}
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		msg.GasPrice = tx.GasPrice()
+	} else {
+		msg.GasFeeCap, msg.GasTipCap = tx.GasFeeCap(), tx.GasTipCap()
+	}
+	return m.Simulate(ctx, msg)
+}
+
+// SimulateTransact simulate calling contract (empty for contract creation) with data by transact options, see Simulate
+func (m *EthereumClient) SimulateTransact(ctx context.Context, opts *bind.TransactOpts, strContract string, data []byte) (*SimulationResult, error) {
+	msg := ethereum.CallMsg{
+		From:      opts.From,
+		Gas:       opts.GasLimit,
+		GasPrice:  opts.GasPrice,
+		GasFeeCap: opts.GasFeeCap,
+		GasTipCap: opts.GasTipCap,
+		Value:     opts.Value,
+		Data:      data,
+	}
+	if strContract != "" {
+		to := Hex2Address(strContract)
+		msg.To = &to
+	}
+	return m.Simulate(ctx, msg)
+}
+
+// simulateByCall simulate by eth_call and eth_estimateGas against pending state when debug_traceCall is not
+// available, the fee caps and access list of message are kept
+func (m *EthereumClient) simulateByCall(ctx context.Context, msg ethereum.CallMsg) (result *SimulationResult, err error) {
+	result = &SimulationResult{}
+	var output hexutil.Bytes
+	err = m.ethcli.Client().CallContext(ctx, &output, "eth_call", toCallArg(msg), "pending")
+	result.ReturnData = output
+	if err != nil {
+		var dataErr rpc.DataError
+		if !errors.As(err, &dataErr) {
+			return nil, err
+		}
+		result.Error = err.Error()
+		if strData, ok := dataErr.ErrorData().(string); ok {
+			result.ReturnData, _ = hexutil.Decode(strData)
+		}
+		to := common.Address{}
+		if msg.To != nil {
+			to = *msg.To
+		}
+		result.RevertReason, _ = m.Registry.UnpackRevert(to.String(), result.ReturnData)
+		return result, nil
+	}
+	result.Success = true
+	result.GasUsed, err = m.estimateGas(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// simulationChanges collect the logs and net ETH/token balance changes of the succeeded frames
+func simulationChanges(frame *CallFrame) (logs []*CallFrameLog, changes []*BalanceChange) {
+	deltas := make(map[[2]common.Address]*big.Int)
+	add := func(addr, token common.Address, delta *big.Int) {
+		key := [2]common.Address{addr, token}
+		if deltas[key] == nil {
+			deltas[key] = new(big.Int)
+		}
+		deltas[key].Add(deltas[key], delta)
+	}
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		if f.Error != "" {
+			return false
+		}
+		if f.Value != nil && f.Value.ToInt().Sign() > 0 && f.Type != "DELEGATECALL" {
+			add(f.From, common.Address{}, new(big.Int).Neg(f.Value.ToInt()))
+			add(f.To, common.Address{}, f.Value.ToInt())
+		}
+		for _, lo := range f.Logs {
+			logs = append(logs, lo)
+			if len(lo.Topics) < 3 || lo.Topics[0] != transferEventID {
+				continue
+			}
+			from, to := common.BytesToAddress(lo.Topics[1][:]), common.BytesToAddress(lo.Topics[2][:])
+			var amount *big.Int
+			switch {
+			case len(lo.Topics) == 4:
+				amount = big.NewInt(1)
+			case len(lo.Data) == 32:
+				amount = new(big.Int).SetBytes(lo.Data)
+			default:
+				continue
+			}
+			add(from, lo.Address, new(big.Int).Neg(amount))
+			add(to, lo.Address, amount)
+		}
+		return true
+	})
+	for key, delta := range deltas {
+		if delta.Sign() == 0 {
+			continue
+		}
+		changes = append(changes, &BalanceChange{Address: key[0], Token: key[1], Delta: delta})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if c := bytes.Compare(changes[i].Token[:], changes[j].Token[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(changes[i].Address[:], changes[j].Address[:]) < 0
+	})
+	return logs, changes
+}
@@ -0,0 +1,142 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeRevertError is an eth_call revert with data
+type fakeRevertError struct {
+	data string
+}
+
+func (e *fakeRevertError) Error() string          { return "execution reverted" }
+func (e *fakeRevertError) ErrorCode() int         { return 3 }
+func (e *fakeRevertError) ErrorData() interface{} { return e.data }
+
+// fakeSimulateNode serve debug_traceCall of frame, or eth_call returning output (reverting by revert if set) and
+// eth_estimateGas of gas, recording the block of eth_call
+type fakeSimulateNode struct {
+	frame  *CallFrame
+	output hexutil.Bytes
+	revert []byte
+	gas    uint64
+	block  string
+}
+
+func (f *fakeSimulateNode) TraceCall(args fakeCallArgs, block string, config map[string]interface{}) *CallFrame {
+	return f.frame
+}
+
+func (f *fakeSimulateNode) Call(args fakeCallArgs, block string) (hexutil.Bytes, error) {
+	f.block = block
+	if f.revert != nil {
+		return nil, &fakeRevertError{data: hexutil.Encode(f.revert)}
+	}
+	return f.output, nil
+}
+
+func (f *fakeSimulateNode) EstimateGas(args fakeCallArgs) hexutil.Uint64 {
+	return hexutil.Uint64(f.gas)
+}
+
+func TestSimulateTrace(t *testing.T) {
+	sender, receiver := common.HexToAddress("0xa1"), common.HexToAddress("0xb1")
+	token, nft, other := common.HexToAddress("0xc1"), common.HexToAddress("0xd1"), common.HexToAddress("0xe1")
+	wei := func(n int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(n)) }
+	// sender pays token 5 wei which forwards 2 wei to receiver, transfers 100 tokens to receiver and receives an
+	// NFT of receiver. The reverted call to other is left out
+	frame := &CallFrame{Type: "CALL", From: sender, To: token, Value: wei(5), GasUsed: 60000, Output: hexutil.Bytes{0x01},
+		Logs: []*CallFrameLog{
+			{Address: token, Topics: []common.Hash{transferEventID, sender.Hash(), receiver.Hash()}, Data: common.BigToHash(big.NewInt(100)).Bytes()},
+		},
+		Calls: []*CallFrame{
+			{Type: "CALL", From: token, To: receiver, Value: wei(2)},
+			{Type: "CALL", From: token, To: nft, Logs: []*CallFrameLog{
+				{Address: nft, Topics: []common.Hash{transferEventID, receiver.Hash(), sender.Hash(), common.BigToHash(big.NewInt(7))}},
+			}},
+			{Type: "CALL", From: token, To: other, Value: wei(9), Error: "execution reverted", Logs: []*CallFrameLog{
+				{Address: token, Topics: []common.Hash{transferEventID, token.Hash(), other.Hash()}, Data: common.BigToHash(big.NewInt(1)).Bytes()},
+			}},
+			// the value of delegate call is the value of its caller
+			{Type: "DELEGATECALL", From: token, To: other, Value: wei(5)},
+		},
+	}
+	m := newTestClient(t, "debug", &fakeSimulateNode{frame: frame})
+	result, err := m.Simulate(context.Background(), ethereum.CallMsg{From: sender, To: &token, Value: big.NewInt(5)})
+	if err != nil {
+		t.Fatalf("simulate error %s", err)
+	}
+	if !result.Success || result.GasUsed != 60000 || result.Trace == nil || len(result.Logs) != 2 {
+		t.Fatalf("simulate result %+v", result)
+	}
+	want := []struct {
+		address, token common.Address
+		delta          int64
+	}{
+		{sender, common.Address{}, -5},
+		{receiver, common.Address{}, 2},
+		{token, common.Address{}, 3},
+		{sender, token, -100},
+		{receiver, token, 100},
+		{sender, nft, 1},
+		{receiver, nft, -1},
+	}
+	if len(result.BalanceChanges) != len(want) {
+		t.Fatalf("%d balance changes, want %d", len(result.BalanceChanges), len(want))
+	}
+	for i, w := range want {
+		c := result.BalanceChanges[i]
+		if c.Address != w.address || c.Token != w.token || c.Delta.Int64() != w.delta {
+			t.Errorf("balance change %d of %s token %s is %s, want %s token %s %d", i, c.Address, c.Token, c.Delta, w.address, w.token, w.delta)
+		}
+	}
+
+	// a failed call has no changes, the revert reason of the failed frame is reported
+	failed := &CallFrame{Type: "CALL", From: sender, To: token, Value: wei(5), Error: "execution reverted",
+		Calls: []*CallFrame{{Type: "CALL", From: token, To: other, Error: "execution reverted", RevertReason: "not allowed"}}}
+	m = newTestClient(t, "debug", &fakeSimulateNode{frame: failed})
+	if result, err = m.Simulate(context.Background(), ethereum.CallMsg{From: sender, To: &token}); err != nil {
+		t.Fatalf("simulate failed call error %s", err)
+	}
+	if result.Success || result.RevertReason != "not allowed" || len(result.BalanceChanges) != 0 {
+		t.Fatalf("simulate failed call result %+v", result)
+	}
+}
+
+func TestSimulateByCall(t *testing.T) {
+	to := common.HexToAddress("0xc1")
+	// without the debug namespace the simulation falls back to eth_call against pending state
+	node := &fakeSimulateNode{output: hexutil.Bytes{0x01}, gas: 21000}
+	m := newTestClient(t, "eth", node)
+	result, err := m.Simulate(context.Background(), ethereum.CallMsg{To: &to})
+	if err != nil {
+		t.Fatalf("simulate by call error %s", err)
+	}
+	if node.block != "pending" {
+		t.Errorf("simulate by eth_call at %q, want pending", node.block)
+	}
+	if !result.Success || result.GasUsed != 21000 || result.Trace != nil || common.Bytes2Hex(result.ReturnData) != "01" {
+		t.Fatalf("simulate by call result %+v", result)
+	}
+
+	stringType, _ := abi.NewType("string", "", nil)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("not allowed")
+	if err != nil {
+		t.Fatalf("pack revert reason error %s", err)
+	}
+	node = &fakeSimulateNode{revert: append(common.CopyBytes(revertSelector), reason...)}
+	m = newTestClient(t, "eth", node)
+	if result, err = m.Simulate(context.Background(), ethereum.CallMsg{To: &to}); err != nil {
+		t.Fatalf("simulate reverted call error %s", err)
+	}
+	if result.Success || result.RevertReason != "not allowed" || result.Error == "" {
+		t.Fatalf("simulate reverted call result %+v", result)
+	}
+}
@@ -0,0 +1,339 @@
+package ethclient
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	siweHeaderSuffix     = " wants you to sign in with your Ethereum account:"
+	siweVersion          = "1"
+	siweURITag           = "URI: "
+	siweVersionTag       = "Version: "
+	siweChainIDTag       = "Chain ID: "
+	siweNonceTag         = "Nonce: "
+	siweIssuedAtTag      = "Issued At: "
+	siweExpirationTag    = "Expiration Time: "
+	siweNotBeforeTag     = "Not Before: "
+	siweRequestIDTag     = "Request ID: "
+	siweResourcesTag     = "Resources:"
+	siweResourcePrefix   = "- "
+	siweNonceAlphabet    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	siweNonceMinLength   = 8
+	siweNonceDefaultSize = 17
+)
+
+// SiweMessage is a Sign-In with Ethereum (EIP-4361) message
+type SiweMessage struct {
+	Scheme         string
+	Domain         string
+	Address        common.Address
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// SiweVerifyOption is the expected values of a SIWE message, empty fields are not checked
+type SiweVerifyOption struct {
+	Domain  string
+	Nonce   string
+	ChainID int64
+	Time    time.Time // time to check expiration time and not before against, default now
+}
+
+// NewSiweNonce generate a random alphanumeric nonce for SIWE message
+func NewSiweNonce() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(siweNonceAlphabet)))
+	for i := 0; i < siweNonceDefaultSize; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(siweNonceAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// ParseSiweMessage parse a SIWE message in the EIP-4361 text format
+func ParseSiweMessage(strMessage string) (msg *SiweMessage, err error) {
+	lines := strings.Split(strMessage, "\n")
+	p := &siweParser{lines: lines}
+	msg = &SiweMessage{}
+
+	header := p.next()
+	if !strings.HasSuffix(header, siweHeaderSuffix) {
+		return nil, fmt.Errorf("siwe message header invalid: %q", header)
+	}
+	msg.Domain = strings.TrimSuffix(header, siweHeaderSuffix)
+	if i := strings.Index(msg.Domain, "://"); i >= 0 {
+		msg.Scheme, msg.Domain = msg.Domain[:i], msg.Domain[i+3:]
+	}
+	if msg.Domain == "" {
+		return nil, fmt.Errorf("siwe message domain undefined")
+	}
+
+	strAddress := p.next()
+	var mixed *common.MixedcaseAddress
+	mixed, err = common.NewMixedcaseAddressFromString(strAddress)
+	if err != nil || !mixed.ValidChecksum() {
+		return nil, fmt.Errorf("siwe message address [%s] is not an EIP-55 checksum address", strAddress)
+	}
+	msg.Address = mixed.Address()
+
+	if p.next() != "" {
+		return nil, fmt.Errorf("siwe message expect empty line after address")
+	}
+	// address LF LF [statement LF] LF: a message without statement has two empty lines
+	if p.peek() != "" {
+		msg.Statement = p.next()
+	}
+	if p.next() != "" {
+		return nil, fmt.Errorf("siwe message expect empty line after statement")
+	}
+
+	if msg.URI, err = p.tag(siweURITag, true); err != nil {
+		return nil, err
+	}
+	if msg.Version, err = p.tag(siweVersionTag, true); err != nil {
+		return nil, err
+	}
+	if msg.Version == "" {
+		// an empty version of the message text is not defaulted as of a message built in code
+		return nil, fmt.Errorf("siwe message version undefined")
+	}
+	var strChainID string
+	if strChainID, err = p.tag(siweChainIDTag, true); err != nil {
+		return nil, err
+	}
+	if msg.ChainID, err = strconv.ParseInt(strChainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("siwe message chain id [%s] invalid", strChainID)
+	}
+	if msg.Nonce, err = p.tag(siweNonceTag, true); err != nil {
+		return nil, err
+	}
+	var strTime string
+	if strTime, err = p.tag(siweIssuedAtTag, true); err != nil {
+		return nil, err
+	}
+	if msg.IssuedAt, err = time.Parse(time.RFC3339Nano, strTime); err != nil {
+		return nil, fmt.Errorf("siwe message issued at [%s] invalid", strTime)
+	}
+	if strTime, err = p.tag(siweExpirationTag, false); err != nil {
+		return nil, err
+	} else if strTime != "" {
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, strTime); err != nil {
+			return nil, fmt.Errorf("siwe message expiration time [%s] invalid", strTime)
+		}
+		msg.ExpirationTime = &t
+	}
+	if strTime, err = p.tag(siweNotBeforeTag, false); err != nil {
+		return nil, err
+	} else if strTime != "" {
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, strTime); err != nil {
+			return nil, fmt.Errorf("siwe message not before [%s] invalid", strTime)
+		}
+		msg.NotBefore = &t
+	}
+	if msg.RequestID, err = p.tag(siweRequestIDTag, false); err != nil {
+		return nil, err
+	}
+	if p.peek() == siweResourcesTag {
+		p.next()
+		for p.more() && strings.HasPrefix(p.peek(), siweResourcePrefix) {
+			msg.Resources = append(msg.Resources, strings.TrimPrefix(p.next(), siweResourcePrefix))
+		}
+	}
+	if p.more() {
+		return nil, fmt.Errorf("siwe message unexpected line: %q", p.peek())
+	}
+	if err = msg.check(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// String render the message in the EIP-4361 text format
+func (msg *SiweMessage) String() string {
+	var sb strings.Builder
+	if msg.Scheme != "" {
+		sb.WriteString(msg.Scheme + "://")
+	}
+	sb.WriteString(msg.Domain + siweHeaderSuffix + "\n")
+	sb.WriteString(msg.Address.String() + "\n\n")
+	if msg.Statement != "" {
+		sb.WriteString(msg.Statement + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(siweURITag + msg.URI + "\n")
+	sb.WriteString(siweVersionTag + msg.version() + "\n")
+	sb.WriteString(siweChainIDTag + strconv.FormatInt(msg.ChainID, 10) + "\n")
+	sb.WriteString(siweNonceTag + msg.Nonce + "\n")
+	sb.WriteString(siweIssuedAtTag + msg.IssuedAt.Format(time.RFC3339Nano))
+	if msg.ExpirationTime != nil {
+		sb.WriteString("\n" + siweExpirationTag + msg.ExpirationTime.Format(time.RFC3339Nano))
+	}
+	if msg.NotBefore != nil {
+		sb.WriteString("\n" + siweNotBeforeTag + msg.NotBefore.Format(time.RFC3339Nano))
+	}
+	if msg.RequestID != "" {
+		sb.WriteString("\n" + siweRequestIDTag + msg.RequestID)
+	}
+	if len(msg.Resources) != 0 {
+		sb.WriteString("\n" + siweResourcesTag)
+		for _, r := range msg.Resources {
+			sb.WriteString("\n" + siweResourcePrefix + r)
+		}
+	}
+	return sb.String()
+}
+
+// Validate check the message fields against the expected domain, nonce and chain id and the time window
+func (msg *SiweMessage) Validate(opt *SiweVerifyOption) error {
+	if err := msg.check(); err != nil {
+		return err
+	}
+	if opt == nil {
+		opt = &SiweVerifyOption{}
+	}
+	if opt.Domain != "" && opt.Domain != msg.Domain {
+		return fmt.Errorf("siwe message domain [%s] mismatch, want [%s]", msg.Domain, opt.Domain)
+	}
+	if opt.Nonce != "" && opt.Nonce != msg.Nonce {
+		return fmt.Errorf("siwe message nonce [%s] mismatch, want [%s]", msg.Nonce, opt.Nonce)
+	}
+	if opt.ChainID != 0 && opt.ChainID != msg.ChainID {
+		return fmt.Errorf("siwe message chain id [%d] mismatch, want [%d]", msg.ChainID, opt.ChainID)
+	}
+	now := opt.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if msg.ExpirationTime != nil && !now.Before(*msg.ExpirationTime) {
+		return fmt.Errorf("siwe message expired at %s", msg.ExpirationTime.Format(time.RFC3339))
+	}
+	if msg.NotBefore != nil && now.Before(*msg.NotBefore) {
+		return fmt.Errorf("siwe message not valid before %s", msg.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// VerifySiweSignature parse and validate the SIWE message and check the personal_sign signature was signed by
+// the message address as an EOA
+func VerifySiweSignature(strMessage string, sig []byte, opt *SiweVerifyOption) (msg *SiweMessage, err error) {
+	msg, err = ParseSiweMessage(strMessage)
+	if err != nil {
+		return nil, err
+	}
+	if err = msg.Validate(opt); err != nil {
+		return nil, err
+	}
+	var ok bool
+	ok, err = VerifyMessage([]byte(strMessage), sig, msg.Address.String())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("siwe message signature not signed by [%s]", msg.Address)
+	}
+	return msg, nil
+}
+
+// VerifySiweMessage parse and validate the SIWE message and check the signature was signed by the message address
+// by EOA recovery or EIP-1271 if the address is a smart-contract wallet
+func (m *EthereumClient) VerifySiweMessage(ctx context.Context, strMessage string, sig []byte, opt *SiweVerifyOption) (msg *SiweMessage, err error) {
+	msg, err = ParseSiweMessage(strMessage)
+	if err != nil {
+		return nil, err
+	}
+	if err = msg.Validate(opt); err != nil {
+		return nil, err
+	}
+	var ok bool
+	ok, err = m.VerifyMessageSignature(ctx, msg.Address.String(), []byte(strMessage), sig)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("siwe message signature not signed by [%s]", msg.Address)
+	}
+	return msg, nil
+}
+
+// version returns the version of message, default "1" if empty
+func (msg *SiweMessage) version() string {
+	if msg.Version == "" {
+		return siweVersion
+	}
+	return msg.Version
+}
+
+func (msg *SiweMessage) check() error {
+	if msg.version() != siweVersion {
+		return fmt.Errorf("siwe message version [%s] unsupported", msg.Version)
+	}
+	if msg.URI == "" {
+		return fmt.Errorf("siwe message uri undefined")
+	}
+	if len(msg.Nonce) < siweNonceMinLength {
+		return fmt.Errorf("siwe message nonce [%s] too short", msg.Nonce)
+	}
+	for _, c := range msg.Nonce {
+		if !strings.ContainsRune(siweNonceAlphabet, c) {
+			return fmt.Errorf("siwe message nonce [%s] is not alphanumeric", msg.Nonce)
+		}
+	}
+	if strings.Contains(msg.Statement, "\n") {
+		return fmt.Errorf("siwe message statement must be a single line")
+	}
+	return nil
+}
+
+type siweParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *siweParser) more() bool {
+	return p.pos < len(p.lines)
+}
+
+func (p *siweParser) peek() string {
+	if !p.more() {
+		return ""
+	}
+	return p.lines[p.pos]
+}
+
+func (p *siweParser) next() string {
+	line := p.peek()
+	p.pos++
+	return line
+}
+
+// tag returns the value of the next line if it starts with tag
+func (p *siweParser) tag(tag string, required bool) (string, error) {
+	if !strings.HasPrefix(p.peek(), tag) {
+		if required {
+			return "", fmt.Errorf("siwe message expect %q, got %q", strings.TrimSpace(tag), p.peek())
+		}
+		return "", nil
+	}
+	return strings.TrimPrefix(p.next(), tag), nil
+}
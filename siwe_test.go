@@ -0,0 +1,127 @@
+package ethclient
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// example from https://eips.ethereum.org/EIPS/eip-4361
+const testSiweMessage = `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ServiceOrg Terms of Service: https://service.invalid/tos
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Expiration Time: 2021-10-01T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseSiweMessage(t *testing.T) {
+	msg, err := ParseSiweMessage(testSiweMessage)
+	if err != nil {
+		t.Fatalf("parse siwe message error %s", err)
+	}
+	if msg.Domain != "service.invalid" || msg.ChainID != 1 || msg.Nonce != "32891756" || len(msg.Resources) != 2 {
+		t.Fatalf("parse siwe message got %+v", msg)
+	}
+	if s := msg.String(); s != testSiweMessage {
+		t.Fatalf("render siwe message got\n%s", s)
+	}
+	issuedAt := msg.IssuedAt
+	if err = msg.Validate(&SiweVerifyOption{Domain: "service.invalid", Nonce: "32891756", ChainID: 1, Time: issuedAt}); err != nil {
+		t.Fatalf("validate siwe message error %s", err)
+	}
+	if err = msg.Validate(&SiweVerifyOption{Domain: "other.invalid", Time: issuedAt}); err == nil {
+		t.Fatalf("validate siwe message with other domain should fail")
+	}
+	if err = msg.Validate(&SiweVerifyOption{Time: issuedAt.Add(48 * time.Hour)}); err == nil {
+		t.Fatalf("validate expired siwe message should fail")
+	}
+	lower := strings.Replace(testSiweMessage, "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", 1)
+	if _, err = ParseSiweMessage(lower); err == nil {
+		t.Fatalf("parse siwe message with non-checksum address should fail")
+	}
+}
+
+func TestParseSiweMessageWithoutStatement(t *testing.T) {
+	// the statement line is omitted but the empty lines around it are kept
+	strMessage := strings.Replace(testSiweMessage, "I accept the ServiceOrg Terms of Service: https://service.invalid/tos\n", "", 1)
+	if !strings.Contains(strMessage, "Cc2\n\n\nURI: ") {
+		t.Fatalf("siwe message without statement is\n%s", strMessage)
+	}
+	msg, err := ParseSiweMessage(strMessage)
+	if err != nil {
+		t.Fatalf("parse siwe message without statement error %s", err)
+	}
+	if msg.Statement != "" || msg.URI != "https://service.invalid/login" {
+		t.Fatalf("parse siwe message without statement got %+v", msg)
+	}
+	if s := msg.String(); s != strMessage {
+		t.Fatalf("render siwe message without statement got\n%s", s)
+	}
+	// a single empty line after address is invalid
+	if _, err = ParseSiweMessage(strings.Replace(strMessage, "\n\n\n", "\n\n", 1)); err == nil {
+		t.Fatalf("parse siwe message of one empty line without statement should fail")
+	}
+}
+
+func TestVerifySiweSignature(t *testing.T) {
+	nonce, err := NewSiweNonce()
+	if err != nil {
+		t.Fatalf("new siwe nonce error %s", err)
+	}
+	msg := &SiweMessage{
+		Scheme:   "https",
+		Domain:   "example.com",
+		Address:  Hex2Address(testAddress),
+		URI:      "https://example.com/login",
+		Version:  "1",
+		ChainID:  1,
+		Nonce:    nonce,
+		IssuedAt: time.Now().UTC(),
+	}
+	strMessage := msg.String()
+	sig, err := SignMessage(testPrivateKey, []byte(strMessage))
+	if err != nil {
+		t.Fatalf("sign siwe message error %s", err)
+	}
+	parsed, err := VerifySiweSignature(strMessage, sig, &SiweVerifyOption{Domain: "example.com", Nonce: nonce})
+	if err != nil {
+		t.Fatalf("verify siwe signature error %s", err)
+	}
+	if parsed.Scheme != "https" || parsed.Address != msg.Address {
+		t.Fatalf("verify siwe signature got %+v", parsed)
+	}
+	if _, err = VerifySiweSignature(strMessage+"\n", sig, nil); err == nil {
+		t.Fatalf("verify tampered siwe message should fail")
+	}
+}
+
+func TestSiweMessageDefaultVersion(t *testing.T) {
+	msg, err := ParseSiweMessage(testSiweMessage)
+	if err != nil {
+		t.Fatalf("parse siwe message error %s", err)
+	}
+	// a message built without version is rendered and validated as of version 1
+	msg.Version = ""
+	if s := msg.String(); s != testSiweMessage {
+		t.Fatalf("render siwe message without version got\n%s", s)
+	}
+	if err = msg.Validate(&SiweVerifyOption{Time: msg.IssuedAt}); err != nil {
+		t.Fatalf("validate siwe message without version error %s", err)
+	}
+	msg.Version = "2"
+	if err = msg.Validate(&SiweVerifyOption{Time: msg.IssuedAt}); err == nil {
+		t.Fatalf("validate siwe message of version 2 should fail")
+	}
+	// the text of message must have the version
+	if _, err = ParseSiweMessage(strings.Replace(testSiweMessage, "Version: 1", "Version: ", 1)); err == nil {
+		t.Fatalf("parse siwe message of empty version should fail")
+	}
+}
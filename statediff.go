@@ -0,0 +1,296 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	prestateTracer = "prestateTracer"
+)
+
+const (
+	TokenERC20  = "ERC20"
+	TokenERC721 = "ERC721"
+)
+
+// transferEventID is the topic of Transfer(address,address,uint256) of both ERC20 and ERC721
+var transferEventID = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// AccountState is an account of the prestateTracer result
+type AccountState struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// StateDiff is the prestateTracer result in diff mode, Pre holds the values before and Post the modified values after
+type StateDiff struct {
+	Pre  map[common.Address]*AccountState `json:"pre"`
+	Post map[common.Address]*AccountState `json:"post"`
+}
+
+// AccountChange is the before and after values of an account modified by a transaction
+type AccountChange struct {
+	Address       common.Address
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+	NonceBefore   uint64
+	NonceAfter    uint64
+	CodeBefore    []byte
+	CodeAfter     []byte
+	Storage       map[common.Hash]*StorageChange
+}
+
+// StorageChange is the before and after values of a storage slot
+type StorageChange struct {
+	Before common.Hash
+	After  common.Hash
+}
+
+// TokenLayout is the storage slots of the balances/owners mappings of a token contract
+type TokenLayout struct {
+	Standard    string // TokenERC20 or TokenERC721
+	BalanceSlot uint64 // slot of mapping(address => uint256) balances
+	OwnersSlot  uint64 // slot of mapping(uint256 => address) owners (ERC721 only)
+}
+
+// TokenBalanceChange is a token balance changed by a transaction
+type TokenBalanceChange struct {
+	Token  common.Address
+	Holder common.Address
+	Before *big.Int
+	After  *big.Int
+}
+
+// TokenOwnerChange is an ERC721 token owner changed by a transaction
+type TokenOwnerChange struct {
+	Token   common.Address
+	TokenId *big.Int
+	Before  common.Address
+	After   common.Address
+}
+
+// TokenChanges is the readable token state changes of a transaction
+type TokenChanges struct {
+	Balances []*TokenBalanceChange
+	Owners   []*TokenOwnerChange
+}
+
+// ERC20Layout returns the storage layout of OpenZeppelin ERC20 (_balances at slot 0)
+func ERC20Layout() *TokenLayout {
+	return &TokenLayout{Standard: TokenERC20, BalanceSlot: 0}
+}
+
+// ERC721Layout returns the storage layout of OpenZeppelin ERC721 (_owners at slot 2, _balances at slot 3)
+func ERC721Layout() *TokenLayout {
+	return &TokenLayout{Standard: TokenERC721, OwnersSlot: 2, BalanceSlot: 3}
+}
+
+// MappingSlot returns the storage slot keccak256(pad32(key) || pad32(slot)) of a mapping entry
+func MappingSlot(key []byte, slot *big.Int) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(key, 32), math.U256Bytes(new(big.Int).Set(slot)))
+}
+
+// TraceStateDiff trace transaction by debug_traceTransaction with prestateTracer in diff mode
+func (m *EthereumClient) TraceStateDiff(ctx context.Context, hash string) (diff *StateDiff, err error) {
+	diff = &StateDiff{}
+	err = m.ethcli.Client().CallContext(ctx, diff, "debug_traceTransaction", Hex2Hash(hash), map[string]interface{}{
+		"tracer":       prestateTracer,
+		"tracerConfig": map[string]interface{}{"diffMode": true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("trace tx [%s] state diff error [%s]", hash, err)
+	}
+	return diff, nil
+}
+
+// TraceTokenChanges trace the state diff of transaction and translate the storage changes of the given tokens
+// (address => layout) into balance and owner changes, the holders and token ids are taken from the Transfer logs
+func (m *EthereumClient) TraceTokenChanges(ctx context.Context, hash string, tokens map[string]*TokenLayout) (changes *TokenChanges, err error) {
+	var diff *StateDiff
+	diff, err = m.TraceStateDiff(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	var receipt *types.Receipt
+	receipt, err = m.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get receipt by hash [%s] error [%s]", hash, err)
+	}
+	return diff.TokenChanges(tokens, receipt.Logs), nil
+}
+
+// TokenChanges translate the storage changes of the given tokens (address => layout) into balance and owner changes,
+// the holders and token ids are taken from the Transfer logs
+func (d *StateDiff) TokenChanges(tokens map[string]*TokenLayout, logs []*types.Log) *TokenChanges {
+	holders := make(map[common.Address][]common.Address)
+	tokenIds := make(map[common.Address][]*big.Int)
+	for _, lo := range logs {
+		if len(lo.Topics) < 3 || lo.Topics[0] != transferEventID {
+			continue
+		}
+		holders[lo.Address] = append(holders[lo.Address], common.BytesToAddress(lo.Topics[1][:]), common.BytesToAddress(lo.Topics[2][:]))
+		if len(lo.Topics) == 4 {
+			tokenIds[lo.Address] = append(tokenIds[lo.Address], lo.Topics[3].Big())
+		}
+	}
+	changes := &TokenChanges{}
+	for strToken, layout := range tokens {
+		token := Hex2Address(strToken)
+		changes.Balances = append(changes.Balances, d.tokenBalanceChanges(token, layout.BalanceSlot, holders[token])...)
+		if layout.Standard == TokenERC721 {
+			changes.Owners = append(changes.Owners, d.tokenOwnerChanges(token, layout.OwnersSlot, tokenIds[token])...)
+		}
+	}
+	return changes
+}
+
+// ERC20BalanceChanges returns the balance changes of holders from the balances mapping at balanceSlot of token
+func (d *StateDiff) ERC20BalanceChanges(strToken string, balanceSlot uint64, holders []string) []*TokenBalanceChange {
+	var addrs []common.Address
+	for _, h := range holders {
+		addrs = append(addrs, Hex2Address(h))
+	}
+	return d.tokenBalanceChanges(Hex2Address(strToken), balanceSlot, addrs)
+}
+
+// ERC721OwnerChanges returns the owner changes of token ids from the owners mapping at ownersSlot of token
+func (d *StateDiff) ERC721OwnerChanges(strToken string, ownersSlot uint64, tokenIds []*big.Int) []*TokenOwnerChange {
+	return d.tokenOwnerChanges(Hex2Address(strToken), ownersSlot, tokenIds)
+}
+
+func (d *StateDiff) tokenBalanceChanges(token common.Address, balanceSlot uint64, holders []common.Address) (changes []*TokenBalanceChange) {
+	seen := make(map[common.Address]bool)
+	for _, holder := range holders {
+		if seen[holder] {
+			continue
+		}
+		seen[holder] = true
+		before, after, ok := d.StorageChange(token, MappingSlot(holder.Bytes(), new(big.Int).SetUint64(balanceSlot)))
+		if !ok {
+			continue
+		}
+		changes = append(changes, &TokenBalanceChange{
+			Token:  token,
+			Holder: holder,
+			Before: before.Big(),
+			After:  after.Big(),
+		})
+	}
+	return changes
+}
+
+func (d *StateDiff) tokenOwnerChanges(token common.Address, ownersSlot uint64, tokenIds []*big.Int) (changes []*TokenOwnerChange) {
+	seen := make(map[string]bool)
+	for _, id := range tokenIds {
+		if seen[id.String()] {
+			continue
+		}
+		seen[id.String()] = true
+		before, after, ok := d.StorageChange(token, MappingSlot(math.U256Bytes(new(big.Int).Set(id)), new(big.Int).SetUint64(ownersSlot)))
+		if !ok {
+			continue
+		}
+		changes = append(changes, &TokenOwnerChange{
+			Token:   token,
+			TokenId: id,
+			Before:  common.BytesToAddress(before.Bytes()),
+			After:   common.BytesToAddress(after.Bytes()),
+		})
+	}
+	return changes
+}
+
+// StorageChange returns the before and after values of a storage slot, ok is false if the slot was not modified
+func (d *StateDiff) StorageChange(addr common.Address, slot common.Hash) (before, after common.Hash, ok bool) {
+	pre, post := d.Pre[addr], d.Post[addr]
+	var inPre, inPost bool
+	if pre != nil {
+		before, inPre = pre.Storage[slot]
+	}
+	if post != nil {
+		after, inPost = post.Storage[slot]
+	}
+	// the diff mode omits the slots cleared to zero from post
+	return before, after, (inPre || inPost) && before != after
+}
+
+// Changes returns the per-account before and after values sorted by address
+func (d *StateDiff) Changes() (changes []*AccountChange) {
+	addrs := make(map[common.Address]bool)
+	for addr := range d.Pre {
+		addrs[addr] = true
+	}
+	for addr := range d.Post {
+		addrs[addr] = true
+	}
+	for addr := range addrs {
+		changes = append(changes, d.accountChange(addr))
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].Address[:], changes[j].Address[:]) < 0
+	})
+	return changes
+}
+
+func (d *StateDiff) accountChange(addr common.Address) *AccountChange {
+	pre, post := d.Pre[addr], d.Post[addr]
+	deleted := post == nil
+	if pre == nil {
+		pre = &AccountState{}
+	}
+	if post == nil {
+		post = &AccountState{}
+	}
+	change := &AccountChange{
+		Address:       addr,
+		BalanceBefore: new(big.Int),
+		BalanceAfter:  new(big.Int),
+		NonceBefore:   pre.Nonce,
+		NonceAfter:    pre.Nonce,
+		CodeBefore:    pre.Code,
+		CodeAfter:     pre.Code,
+		Storage:       make(map[common.Hash]*StorageChange),
+	}
+	if pre.Balance != nil {
+		change.BalanceBefore = pre.Balance.ToInt()
+		change.BalanceAfter = pre.Balance.ToInt()
+	}
+	// the diff mode omits the unchanged fields from post and the account from post if it was deleted
+	switch {
+	case deleted:
+		change.BalanceAfter, change.NonceAfter, change.CodeAfter = new(big.Int), 0, nil
+	default:
+		if post.Balance != nil {
+			change.BalanceAfter = post.Balance.ToInt()
+		}
+		if post.Nonce != 0 {
+			change.NonceAfter = post.Nonce
+		}
+		if post.Code != nil {
+			change.CodeAfter = post.Code
+		}
+	}
+	for slot := range pre.Storage {
+		if before, after, ok := d.StorageChange(addr, slot); ok {
+			change.Storage[slot] = &StorageChange{Before: before, After: after}
+		}
+	}
+	for slot := range post.Storage {
+		if before, after, ok := d.StorageChange(addr, slot); ok {
+			change.Storage[slot] = &StorageChange{Before: before, After: after}
+		}
+	}
+	return change
+}
@@ -0,0 +1,141 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testStateDiff is a prestateTracer diff of a token transfer of 0x..a1 to 0x..a2, paid by sender 0x..a1, creating
+// contract 0x..c1 and self-destructing 0x..d1. Post omits the unchanged fields and the slots cleared to zero
+const testStateDiff = `{
+"pre":{
+ "0x00000000000000000000000000000000000000a1":{"balance":"0x1000","nonce":5},
+ "0x00000000000000000000000000000000000000b1":{"balance":"0x0","nonce":1,"code":"0x6080","storage":{
+  "0x5f68209e2ec6c6ebc1bc9a2b4cc2b47b4b5e5d5b4e3b6f2a19cb0e4a8ea2f4d1":"0x0000000000000000000000000000000000000000000000000000000000000064",
+  "0x0000000000000000000000000000000000000000000000000000000000000009":"0x0000000000000000000000000000000000000000000000000000000000000001"}},
+ "0x00000000000000000000000000000000000000d1":{"balance":"0x7","nonce":1,"code":"0x60806040"}
+},
+"post":{
+ "0x00000000000000000000000000000000000000a1":{"balance":"0xf00","nonce":6},
+ "0x00000000000000000000000000000000000000b1":{"storage":{
+  "0x0000000000000000000000000000000000000000000000000000000000000002":"0x0000000000000000000000000000000000000000000000000000000000000003"}},
+ "0x00000000000000000000000000000000000000c1":{"balance":"0x1","nonce":1,"code":"0x6001"}
+}}`
+
+func TestStateDiffChanges(t *testing.T) {
+	var diff StateDiff
+	if err := json.Unmarshal([]byte(testStateDiff), &diff); err != nil {
+		t.Fatalf("unmarshal state diff error %s", err)
+	}
+	changes := diff.Changes()
+	if len(changes) != 4 {
+		t.Fatalf("%d account changes, want 4", len(changes))
+	}
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+	balanceSlot := common.HexToHash("0x5f68209e2ec6c6ebc1bc9a2b4cc2b47b4b5e5d5b4e3b6f2a19cb0e4a8ea2f4d1")
+	tests := []struct {
+		address                     string
+		balanceBefore, balanceAfter int64
+		nonceBefore, nonceAfter     uint64
+		codeBefore, codeAfter       string
+		storage                     map[common.Hash]StorageChange
+	}{
+		// sender paid the fee and bumped the nonce
+		{"0xa1", 0x1000, 0xf00, 5, 6, "", "", map[common.Hash]StorageChange{}},
+		// token contract keeps the omitted balance, nonce and code, a slot cleared and a slot set
+		{"0xb1", 0, 0, 1, 1, "6080", "6080", map[common.Hash]StorageChange{
+			balanceSlot: {Before: slot(0x64), After: common.Hash{}},
+			slot(9):     {Before: slot(1), After: common.Hash{}},
+			slot(2):     {Before: common.Hash{}, After: slot(3)},
+		}},
+		{"0xc1", 0, 1, 0, 1, "", "6001", map[common.Hash]StorageChange{}},
+		{"0xd1", 7, 0, 1, 0, "60806040", "", map[common.Hash]StorageChange{}},
+	}
+	for i, tt := range tests {
+		c := changes[i]
+		if c.Address != common.HexToAddress(tt.address) {
+			t.Fatalf("change %d of %s, want %s", i, c.Address, tt.address)
+		}
+		if c.BalanceBefore.Int64() != tt.balanceBefore || c.BalanceAfter.Int64() != tt.balanceAfter ||
+			c.NonceBefore != tt.nonceBefore || c.NonceAfter != tt.nonceAfter ||
+			common.Bytes2Hex(c.CodeBefore) != tt.codeBefore || common.Bytes2Hex(c.CodeAfter) != tt.codeAfter {
+			t.Errorf("%s changed balance %s => %s nonce %d => %d code %x => %x", tt.address, c.BalanceBefore, c.BalanceAfter,
+				c.NonceBefore, c.NonceAfter, c.CodeBefore, c.CodeAfter)
+		}
+		if len(c.Storage) != len(tt.storage) {
+			t.Errorf("%s changed %d slots, want %d", tt.address, len(c.Storage), len(tt.storage))
+		}
+		for key, want := range tt.storage {
+			if got := c.Storage[key]; got == nil || *got != want {
+				t.Errorf("%s slot %s changed %v, want %v", tt.address, key.Hex(), got, want)
+			}
+		}
+	}
+	if _, _, ok := diff.StorageChange(common.HexToAddress("0xb1"), slot(7)); ok {
+		t.Errorf("untouched slot reported changed")
+	}
+}
+
+func TestStateDiffTokenChanges(t *testing.T) {
+	token, nft := common.HexToAddress("0xe1"), common.HexToAddress("0xe2")
+	from, to := common.HexToAddress("0xa1"), common.HexToAddress("0xa2")
+	tokenId := big.NewInt(42)
+	word := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+	diff := &StateDiff{
+		Pre: map[common.Address]*AccountState{
+			token: {Storage: map[common.Hash]common.Hash{
+				MappingSlot(from.Bytes(), big.NewInt(0)): word(100),
+			}},
+			nft: {Storage: map[common.Hash]common.Hash{
+				MappingSlot(from.Bytes(), big.NewInt(3)):    word(1),
+				MappingSlot(tokenId.Bytes(), big.NewInt(2)): from.Hash(),
+			}},
+		},
+		Post: map[common.Address]*AccountState{
+			token: {Storage: map[common.Hash]common.Hash{
+				MappingSlot(from.Bytes(), big.NewInt(0)): word(70),
+				MappingSlot(to.Bytes(), big.NewInt(0)):   word(30),
+			}},
+			nft: {Storage: map[common.Hash]common.Hash{
+				MappingSlot(to.Bytes(), big.NewInt(3)):      word(1),
+				MappingSlot(tokenId.Bytes(), big.NewInt(2)): to.Hash(),
+			}},
+		},
+	}
+	logs := []*types.Log{
+		{Address: token, Topics: []common.Hash{transferEventID, from.Hash(), to.Hash()}, Data: word(30).Bytes()},
+		{Address: nft, Topics: []common.Hash{transferEventID, from.Hash(), to.Hash(), common.BigToHash(tokenId)}},
+		// other events and tokens are ignored
+		{Address: token, Topics: []common.Hash{word(1), from.Hash(), to.Hash()}},
+		{Address: common.HexToAddress("0xe3"), Topics: []common.Hash{transferEventID, from.Hash(), to.Hash()}},
+	}
+	changes := diff.TokenChanges(map[string]*TokenLayout{token.Hex(): ERC20Layout()}, logs)
+	if len(changes.Balances) != 2 || len(changes.Owners) != 0 {
+		t.Fatalf("ERC20 token changes %d balances %d owners", len(changes.Balances), len(changes.Owners))
+	}
+	want := map[common.Address][2]int64{from: {100, 70}, to: {0, 30}}
+	for _, b := range changes.Balances {
+		if b.Token != token || b.Before.Int64() != want[b.Holder][0] || b.After.Int64() != want[b.Holder][1] {
+			t.Errorf("balance of %s changed %s => %s", b.Holder, b.Before, b.After)
+		}
+	}
+
+	changes = diff.TokenChanges(map[string]*TokenLayout{nft.Hex(): ERC721Layout()}, logs)
+	if len(changes.Balances) != 2 || len(changes.Owners) != 1 {
+		t.Fatalf("ERC721 token changes %d balances %d owners", len(changes.Balances), len(changes.Owners))
+	}
+	// the balance of sender is cleared to zero and omitted from post
+	want = map[common.Address][2]int64{from: {1, 0}, to: {0, 1}}
+	for _, b := range changes.Balances {
+		if b.Before.Int64() != want[b.Holder][0] || b.After.Int64() != want[b.Holder][1] {
+			t.Errorf("ERC721 balance of %s changed %s => %s", b.Holder, b.Before, b.After)
+		}
+	}
+	if o := changes.Owners[0]; o.TokenId.Cmp(tokenId) != 0 || o.Before != from || o.After != to {
+		t.Errorf("owner of token %s changed %s => %s", o.TokenId, o.Before, o.After)
+	}
+}
@@ -0,0 +1,489 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	storageEncodingInplace      = "inplace"
+	storageEncodingMapping      = "mapping"
+	storageEncodingDynamicArray = "dynamic_array"
+	storageEncodingBytes        = "bytes"
+)
+
+// StorageLayout is the solc storage layout (solc --storage-layout or outputSelection "storageLayout") of a contract
+type StorageLayout struct {
+	Storage []*StorageVariable      `json:"storage"`
+	Types   map[string]*StorageType `json:"types"`
+}
+
+// StorageVariable is a state variable of storage layout or a member of struct type
+type StorageVariable struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"` // byte offset in the slot, from the right
+	Slot   string `json:"slot"`   // decimal slot number, relative to the struct for members
+	Type   string `json:"type"`   // type identifier, key of StorageLayout.Types
+}
+
+// StorageType is a type of storage layout
+type StorageType struct {
+	Encoding      string             `json:"encoding"` // inplace, mapping, dynamic_array or bytes
+	Label         string             `json:"label"`    // e.g. uint256, mapping(address => uint256), struct S
+	NumberOfBytes string             `json:"numberOfBytes"`
+	Key           string             `json:"key,omitempty"`     // key type of mapping
+	Value         string             `json:"value,omitempty"`   // value type of mapping
+	Base          string             `json:"base,omitempty"`    // element type of array
+	Members       []*StorageVariable `json:"members,omitempty"` // members of struct
+}
+
+// StorageLocation is the storage position of a variable path
+type StorageLocation struct {
+	Slot   common.Hash
+	Offset int
+	Type   *StorageType
+}
+
+// storageReader locate and decode the variables of a storage layout, the slots are read by storage or located
+// only (storage is nil)
+type storageReader struct {
+	layout  *StorageLayout
+	storage func(slot common.Hash) ([]byte, error)
+	words   map[common.Hash][]byte
+}
+
+// storagePosition is the slot and the byte offset in slot of a storage value
+type storagePosition struct {
+	slot   *big.Int
+	offset int
+	typ    *StorageType
+}
+
+// LoadStorageLayout load a solc storage layout from JSON file or string, the JSON may also be a contract artifact
+// with a "storageLayout" field
+func LoadStorageLayout(strLayout string) (*StorageLayout, error) {
+	data := []byte(strLayout)
+	if !strings.HasPrefix(strings.TrimSpace(strLayout), "{") {
+		var err error
+		if data, err = os.ReadFile(strLayout); err != nil {
+			return nil, fmt.Errorf("read storage layout file %s error: %s", strLayout, err.Error())
+		}
+	}
+	var layout struct {
+		StorageLayout
+		Artifact *StorageLayout `json:"storageLayout"`
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("unmarshal storage layout json error: %s", err.Error())
+	}
+	if layout.Artifact != nil {
+		return layout.Artifact, nil
+	}
+	if layout.Storage == nil {
+		return nil, fmt.Errorf("json has no storage layout")
+	}
+	return &layout.StorageLayout, nil
+}
+
+// Locate returns the storage slot of a variable path such as totalSupply, balances[0xabc], owners[5] or
+// users[0xabc].tokens[2].amount, the index of a dynamic array is not checked against its length
+func (l *StorageLayout) Locate(path string) (*StorageLocation, error) {
+	r := &storageReader{layout: l}
+	pos, err := r.locate(path)
+	if err != nil {
+		return nil, err
+	}
+	return &StorageLocation{Slot: slotHash(pos.slot), Offset: pos.offset, Type: pos.typ}, nil
+}
+
+// ReadStorage read and decode the variable path of contract by its storage layout at block number.
+// Value types are decoded as the go-ethereum ABI types (uint8..uint64, *big.Int, common.Address, bool, [N]byte),
+// string and bytes as string and []byte, structs as map[string]interface{} and arrays as []interface{}. The mapping
+// members of structs are left out as mappings can only be read by key
+func (m *EthereumClient) ReadStorage(ctx context.Context, strContract string, layout *StorageLayout, path string, number uint64) (interface{}, error) {
+	contract := Hex2Address(strContract)
+	return layout.read(path, func(slot common.Hash) ([]byte, error) {
+		word, err := m.ethcli.StorageAt(ctx, contract, slot, big.NewInt(int64(number)))
+		if err != nil {
+			return nil, fmt.Errorf("get storage [%s] of [%s] error [%s]", slot, contract, err)
+		}
+		return word, nil
+	})
+}
+
+// read locate and decode the variable path, the slots are read by storage
+func (l *StorageLayout) read(path string, storage func(slot common.Hash) ([]byte, error)) (interface{}, error) {
+	r := &storageReader{
+		layout:  l,
+		storage: storage,
+		words:   make(map[common.Hash][]byte),
+	}
+	pos, err := r.locate(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.decode(pos)
+}
+
+func (r *storageReader) typ(id string) (*StorageType, error) {
+	typ, ok := r.layout.Types[id]
+	if !ok {
+		return nil, fmt.Errorf("storage type [%s] not found in layout", id)
+	}
+	return typ, nil
+}
+
+// locate walk the variable path: identifier, .member and [key or index]
+func (r *storageReader) locate(path string) (pos *storagePosition, err error) {
+	rest := strings.TrimSpace(path)
+	name, rest := splitPathIdent(rest)
+	for _, v := range r.layout.Storage {
+		if v.Label == name {
+			if pos, err = r.position(big.NewInt(0), v); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("storage variable [%s] not found in layout", name)
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			name, rest = splitPathIdent(rest[1:])
+			if pos, err = r.member(pos, name); err != nil {
+				return nil, err
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if q := rest[1:min(2, len(rest))]; q == `"` || q == "'" {
+				// quoted string key may contain ']'
+				if i := strings.Index(rest[2:], q+"]"); i >= 0 {
+					end = i + 3
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("path [%s] missing ']'", path)
+			}
+			key := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if pos, err = r.index(pos, key); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("path [%s] invalid at [%s]", path, rest)
+		}
+	}
+	return pos, nil
+}
+
+// splitPathIdent split the leading identifier of path
+func splitPathIdent(path string) (ident, rest string) {
+	i := strings.IndexAny(path, ".[")
+	if i < 0 {
+		return strings.TrimSpace(path), ""
+	}
+	return strings.TrimSpace(path[:i]), path[i:]
+}
+
+// position returns the position of a variable or struct member relative to base slot
+func (r *storageReader) position(base *big.Int, v *StorageVariable) (*storagePosition, error) {
+	slot, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return nil, fmt.Errorf("storage variable [%s] slot [%s] invalid", v.Label, v.Slot)
+	}
+	typ, err := r.typ(v.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &storagePosition{slot: slot.Add(slot, base), offset: v.Offset, typ: typ}, nil
+}
+
+func (r *storageReader) member(pos *storagePosition, name string) (*storagePosition, error) {
+	if pos.typ.Members == nil {
+		return nil, fmt.Errorf("type [%s] has no member [%s]", pos.typ.Label, name)
+	}
+	for _, v := range pos.typ.Members {
+		if v.Label == name {
+			return r.position(pos.slot, v)
+		}
+	}
+	return nil, fmt.Errorf("type [%s] has no member [%s]", pos.typ.Label, name)
+}
+
+func (r *storageReader) index(pos *storagePosition, key string) (*storagePosition, error) {
+	switch {
+	case pos.typ.Encoding == storageEncodingMapping:
+		keyType, err := r.typ(pos.typ.Key)
+		if err != nil {
+			return nil, err
+		}
+		valueType, err := r.typ(pos.typ.Value)
+		if err != nil {
+			return nil, err
+		}
+		slot, err := mappingKeySlot(keyType, key, pos.slot)
+		if err != nil {
+			return nil, err
+		}
+		return &storagePosition{slot: slot.Big(), typ: valueType}, nil
+	case pos.typ.Base != "":
+		idx, ok := math.ParseBig256(key)
+		if !ok || idx.Sign() < 0 {
+			return nil, fmt.Errorf("array index [%s] of [%s] invalid", key, pos.typ.Label)
+		}
+		length, base, err := r.arrayBase(pos)
+		if err != nil {
+			return nil, err
+		}
+		if length != nil && idx.Cmp(length) >= 0 {
+			return nil, fmt.Errorf("array index %s of [%s] out of length %s", idx, pos.typ.Label, length)
+		}
+		return r.element(base, idx, pos.typ)
+	}
+	return nil, fmt.Errorf("type [%s] is not a mapping or array", pos.typ.Label)
+}
+
+// arrayBase returns the length (nil if unknown) and the first slot of array elements
+func (r *storageReader) arrayBase(pos *storagePosition) (length, base *big.Int, err error) {
+	if pos.typ.Encoding == storageEncodingDynamicArray {
+		base = crypto.Keccak256Hash(math.U256Bytes(new(big.Int).Set(pos.slot))).Big()
+		if r.storage == nil {
+			return nil, base, nil
+		}
+		var word []byte
+		if word, err = r.word(pos.slot); err != nil {
+			return nil, nil, err
+		}
+		return new(big.Int).SetBytes(word), base, nil
+	}
+	// static array label is <base label>[N]
+	i := strings.LastIndexByte(pos.typ.Label, '[')
+	n, err := strconv.ParseUint(strings.TrimSuffix(pos.typ.Label[i+1:], "]"), 10, 64)
+	if i < 0 || err != nil {
+		return nil, nil, fmt.Errorf("static array [%s] length unknown", pos.typ.Label)
+	}
+	return new(big.Int).SetUint64(n), pos.slot, nil
+}
+
+// element returns the position of array element, elements not longer than 16 bytes are packed into slots
+func (r *storageReader) element(base, idx *big.Int, arrayType *StorageType) (*storagePosition, error) {
+	typ, err := r.typ(arrayType.Base)
+	if err != nil {
+		return nil, err
+	}
+	size, err := strconv.Atoi(typ.NumberOfBytes)
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("storage type [%s] size [%s] invalid", typ.Label, typ.NumberOfBytes)
+	}
+	if size <= 16 {
+		perSlot := big.NewInt(int64(32 / size))
+		slot, offset := new(big.Int).DivMod(idx, perSlot, new(big.Int))
+		return &storagePosition{slot: slot.Add(slot, base), offset: int(offset.Int64()) * size, typ: typ}, nil
+	}
+	slot := new(big.Int).Mul(idx, big.NewInt(int64((size+31)/32)))
+	return &storagePosition{slot: slot.Add(slot, base), typ: typ}, nil
+}
+
+// mappingKeySlot returns the slot keccak256(h(key) || pad32(slot)) of mapping entry, h pads value type keys to
+// 32 bytes as ABI encoding and keeps string and bytes keys unpadded
+func mappingKeySlot(keyType *StorageType, key string, slot *big.Int) (common.Hash, error) {
+	if keyType.Encoding == storageEncodingBytes {
+		raw := []byte(strings.Trim(key, `"'`))
+		if keyType.Label == "bytes" {
+			var err error
+			if raw, err = hexutil.Decode(key); err != nil {
+				return common.Hash{}, fmt.Errorf("mapping key [%s] is not a bytes hex", key)
+			}
+		}
+		return crypto.Keccak256Hash(raw, math.U256Bytes(new(big.Int).Set(slot))), nil
+	}
+	typ, err := storageABIType(keyType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	value, err := ConvertArg(typ, strings.Trim(key, `"'`))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("mapping key [%s] of type [%s] invalid [%s]", key, keyType.Label, err)
+	}
+	encoded, err := abi.Arguments{{Type: typ}}.Pack(value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return MappingSlot(encoded, slot), nil
+}
+
+// storageABIType returns the ABI type of a storage value type, contracts are addresses and enums are uint8
+func storageABIType(typ *StorageType) (abi.Type, error) {
+	label := typ.Label
+	switch {
+	case strings.HasPrefix(label, "contract ") || strings.HasPrefix(label, "address"):
+		label = "address"
+	case strings.HasPrefix(label, "enum "):
+		label = "uint8"
+	}
+	t, err := abi.NewType(label, "", nil)
+	if err != nil {
+		return abi.Type{}, fmt.Errorf("storage type [%s] not supported", typ.Label)
+	}
+	return t, nil
+}
+
+func (r *storageReader) word(slot *big.Int) ([]byte, error) {
+	key := slotHash(slot)
+	if word, ok := r.words[key]; ok {
+		return word, nil
+	}
+	word, err := r.storage(key)
+	if err != nil {
+		return nil, err
+	}
+	word = common.LeftPadBytes(word, 32)
+	r.words[key] = word
+	return word, nil
+}
+
+func (r *storageReader) decode(pos *storagePosition) (interface{}, error) {
+	typ := pos.typ
+	switch typ.Encoding {
+	case storageEncodingMapping:
+		return nil, fmt.Errorf("type [%s] can not be read without a key", typ.Label)
+	case storageEncodingBytes:
+		return r.decodeBytes(pos)
+	case storageEncodingDynamicArray:
+		return r.decodeArray(pos)
+	case storageEncodingInplace:
+		if typ.Members != nil {
+			values := make(map[string]interface{}, len(typ.Members))
+			for _, v := range typ.Members {
+				member, err := r.position(pos.slot, v)
+				if err != nil {
+					return nil, err
+				}
+				if member.typ.Encoding == storageEncodingMapping {
+					// read by key with a path such as s.balances[0xabc]
+					continue
+				}
+				if values[v.Label], err = r.decode(member); err != nil {
+					return nil, err
+				}
+			}
+			return values, nil
+		}
+		if typ.Base != "" {
+			return r.decodeArray(pos)
+		}
+		return r.decodeValue(pos)
+	}
+	return nil, fmt.Errorf("storage encoding [%s] of type [%s] not supported", typ.Encoding, typ.Label)
+}
+
+// decodeValue decode the value type packed at offset of slot
+func (r *storageReader) decodeValue(pos *storagePosition) (interface{}, error) {
+	size, err := strconv.Atoi(pos.typ.NumberOfBytes)
+	if err != nil || size <= 0 || pos.offset+size > 32 {
+		return nil, fmt.Errorf("storage type [%s] size [%s] invalid", pos.typ.Label, pos.typ.NumberOfBytes)
+	}
+	word, err := r.word(pos.slot)
+	if err != nil {
+		return nil, err
+	}
+	raw := word[32-pos.offset-size : 32-pos.offset]
+	typ, err := storageABIType(pos.typ)
+	if err != nil {
+		// function pointers and other types are returned raw
+		return common.CopyBytes(raw), nil
+	}
+	encoded := make([]byte, 32)
+	switch {
+	case typ.T == abi.FixedBytesTy:
+		copy(encoded, raw)
+	case typ.T == abi.IntTy && raw[0]&0x80 != 0:
+		for i := range encoded {
+			encoded[i] = 0xff
+		}
+		fallthrough
+	default:
+		copy(encoded[32-size:], raw)
+	}
+	values, err := abi.Arguments{{Type: typ}}.UnpackValues(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode storage type [%s] error [%s]", pos.typ.Label, err)
+	}
+	return values[0], nil
+}
+
+// decodeBytes decode string or bytes, the data is kept in the slot with length*2 in the lowest byte if shorter
+// than 32 bytes, otherwise the slot is length*2+1 and the data starts at slot keccak256(slot)
+func (r *storageReader) decodeBytes(pos *storagePosition) (interface{}, error) {
+	word, err := r.word(pos.slot)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if word[31]&1 == 0 {
+		if word[31]/2 > 31 {
+			return nil, fmt.Errorf("type [%s] short length %d of slot %s invalid", pos.typ.Label, word[31]/2, slotHash(pos.slot))
+		}
+		data = common.CopyBytes(word[:word[31]/2])
+	} else {
+		length := new(big.Int).SetBytes(word)
+		length.Rsh(length, 1)
+		if !length.IsUint64() || length.Uint64() > 1<<24 {
+			return nil, fmt.Errorf("type [%s] length %s too large", pos.typ.Label, length)
+		}
+		n := int(length.Uint64())
+		slot := crypto.Keccak256Hash(math.U256Bytes(new(big.Int).Set(pos.slot))).Big()
+		for len(data) < n {
+			if word, err = r.word(slot); err != nil {
+				return nil, err
+			}
+			data = append(data, word...)
+			slot.Add(slot, big.NewInt(1))
+		}
+		data = data[:n]
+	}
+	if pos.typ.Label == "string" {
+		return string(data), nil
+	}
+	return data, nil
+}
+
+// decodeArray decode all the elements of a static or dynamic array
+func (r *storageReader) decodeArray(pos *storagePosition) (interface{}, error) {
+	length, base, err := r.arrayBase(pos)
+	if err != nil {
+		return nil, err
+	}
+	if !length.IsUint64() || length.Uint64() > 1<<16 {
+		return nil, fmt.Errorf("array [%s] length %s too large to read at once, read by index", pos.typ.Label, length)
+	}
+	values := make([]interface{}, length.Uint64())
+	for i := range values {
+		elem, err := r.element(base, big.NewInt(int64(i)), pos.typ)
+		if err != nil {
+			return nil, err
+		}
+		if values[i], err = r.decode(elem); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// slotHash returns the 32 bytes slot key of slot number modulo 2^256
+func slotHash(slot *big.Int) common.Hash {
+	return common.BigToHash(math.U256(new(big.Int).Set(slot)))
+}
@@ -0,0 +1,269 @@
+package ethclient
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testStorageLayout is the layout of
+//
+//	contract C {
+//	    struct S { uint256 amount; address owner; uint32[] ids; mapping(address => bool) approved; }
+//	    uint128 a; uint64 b; bool c; int8 d; bytes4 sel;      // slot 0
+//	    mapping(address => S) users;                         // slot 1
+//	    address[] owners;                                    // slot 2
+//	    string name;                                         // slot 3
+//	    mapping(string => uint256) byName;                   // slot 4
+//	    uint16[3] small;                                     // slot 5
+//	    mapping(address => mapping(address => uint256)) allowances; // slot 6
+//	    bytes data;                                          // slot 7
+//	    mapping(int256 => uint256) byInt;                    // slot 8
+//	}
+const testStorageLayout = `{"storageLayout":{"storage":[
+{"label":"a","offset":0,"slot":"0","type":"t_uint128"},
+{"label":"b","offset":16,"slot":"0","type":"t_uint64"},
+{"label":"c","offset":24,"slot":"0","type":"t_bool"},
+{"label":"d","offset":25,"slot":"0","type":"t_int8"},
+{"label":"sel","offset":26,"slot":"0","type":"t_bytes4"},
+{"label":"users","offset":0,"slot":"1","type":"t_mapping(t_address,t_struct(S)1_storage)"},
+{"label":"owners","offset":0,"slot":"2","type":"t_array(t_address)dyn_storage"},
+{"label":"name","offset":0,"slot":"3","type":"t_string_storage"},
+{"label":"byName","offset":0,"slot":"4","type":"t_mapping(t_string_memory_ptr,t_uint256)"},
+{"label":"small","offset":0,"slot":"5","type":"t_array(t_uint16)3_storage"},
+{"label":"allowances","offset":0,"slot":"6","type":"t_mapping(t_address,t_mapping(t_address,t_uint256))"},
+{"label":"data","offset":0,"slot":"7","type":"t_bytes_storage"},
+{"label":"byInt","offset":0,"slot":"8","type":"t_mapping(t_int256,t_uint256)"}
+],"types":{
+"t_uint128":{"encoding":"inplace","label":"uint128","numberOfBytes":"16"},
+"t_uint64":{"encoding":"inplace","label":"uint64","numberOfBytes":"8"},
+"t_uint32":{"encoding":"inplace","label":"uint32","numberOfBytes":"4"},
+"t_uint16":{"encoding":"inplace","label":"uint16","numberOfBytes":"2"},
+"t_uint256":{"encoding":"inplace","label":"uint256","numberOfBytes":"32"},
+"t_int256":{"encoding":"inplace","label":"int256","numberOfBytes":"32"},
+"t_bool":{"encoding":"inplace","label":"bool","numberOfBytes":"1"},
+"t_int8":{"encoding":"inplace","label":"int8","numberOfBytes":"1"},
+"t_bytes4":{"encoding":"inplace","label":"bytes4","numberOfBytes":"4"},
+"t_address":{"encoding":"inplace","label":"address","numberOfBytes":"20"},
+"t_string_storage":{"encoding":"bytes","label":"string","numberOfBytes":"32"},
+"t_string_memory_ptr":{"encoding":"bytes","label":"string","numberOfBytes":"32"},
+"t_bytes_storage":{"encoding":"bytes","label":"bytes","numberOfBytes":"32"},
+"t_array(t_address)dyn_storage":{"encoding":"dynamic_array","base":"t_address","label":"address[]","numberOfBytes":"32"},
+"t_array(t_uint32)dyn_storage":{"encoding":"dynamic_array","base":"t_uint32","label":"uint32[]","numberOfBytes":"32"},
+"t_array(t_uint16)3_storage":{"encoding":"inplace","base":"t_uint16","label":"uint16[3]","numberOfBytes":"32"},
+"t_mapping(t_address,t_bool)":{"encoding":"mapping","key":"t_address","value":"t_bool","label":"mapping(address => bool)","numberOfBytes":"32"},
+"t_mapping(t_address,t_uint256)":{"encoding":"mapping","key":"t_address","value":"t_uint256","label":"mapping(address => uint256)","numberOfBytes":"32"},
+"t_mapping(t_address,t_mapping(t_address,t_uint256))":{"encoding":"mapping","key":"t_address","value":"t_mapping(t_address,t_uint256)","label":"mapping(address => mapping(address => uint256))","numberOfBytes":"32"},
+"t_mapping(t_address,t_struct(S)1_storage)":{"encoding":"mapping","key":"t_address","value":"t_struct(S)1_storage","label":"mapping(address => struct C.S)","numberOfBytes":"32"},
+"t_mapping(t_string_memory_ptr,t_uint256)":{"encoding":"mapping","key":"t_string_memory_ptr","value":"t_uint256","label":"mapping(string => uint256)","numberOfBytes":"32"},
+"t_mapping(t_int256,t_uint256)":{"encoding":"mapping","key":"t_int256","value":"t_uint256","label":"mapping(int256 => uint256)","numberOfBytes":"32"},
+"t_struct(S)1_storage":{"encoding":"inplace","label":"struct C.S","numberOfBytes":"128","members":[
+ {"label":"amount","offset":0,"slot":"0","type":"t_uint256"},
+ {"label":"owner","offset":0,"slot":"1","type":"t_address"},
+ {"label":"ids","offset":0,"slot":"2","type":"t_array(t_uint32)dyn_storage"},
+ {"label":"approved","offset":0,"slot":"3","type":"t_mapping(t_address,t_bool)"}]}
+}}}`
+
+var (
+	testHolder  = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testSpender = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+func slotN(n int64) common.Hash {
+	return common.BigToHash(big.NewInt(n))
+}
+
+func slotAdd(slot common.Hash, n int64) common.Hash {
+	return slotHash(new(big.Int).Add(slot.Big(), big.NewInt(n)))
+}
+
+func arraySlot(slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(slot.Bytes())
+}
+
+func TestLoadStorageLayout(t *testing.T) {
+	layout, err := LoadStorageLayout(testStorageLayout)
+	if err != nil {
+		t.Fatalf("load storage layout error %s", err)
+	}
+	if len(layout.Storage) != 13 || len(layout.Types["t_struct(S)1_storage"].Members) != 4 {
+		t.Fatalf("loaded %d variables", len(layout.Storage))
+	}
+	// the layout without the artifact wrapper
+	inner := strings.TrimSuffix(strings.TrimPrefix(testStorageLayout, `{"storageLayout":`), "}")
+	if layout, err = LoadStorageLayout(inner); err != nil || len(layout.Storage) != 13 {
+		t.Fatalf("load inner storage layout error %v", err)
+	}
+	if _, err = LoadStorageLayout(`{"abi":[]}`); err == nil {
+		t.Errorf("load json without storage layout should fail")
+	}
+}
+
+func TestStorageLocate(t *testing.T) {
+	layout, err := LoadStorageLayout(testStorageLayout)
+	if err != nil {
+		t.Fatalf("load storage layout error %s", err)
+	}
+	user := MappingSlot(testHolder.Bytes(), big.NewInt(1))
+	owners := arraySlot(slotN(2))
+	tests := []struct {
+		path   string
+		slot   common.Hash
+		offset int
+		typ    string
+	}{
+		{"a", slotN(0), 0, "uint128"},
+		{"b", slotN(0), 16, "uint64"},
+		{"d", slotN(0), 25, "int8"},
+		{"sel", slotN(0), 26, "bytes4"},
+		{"users[" + testHolder.Hex() + "]", user, 0, "struct C.S"},
+		{"users[" + testHolder.Hex() + "].owner", slotAdd(user, 1), 0, "address"},
+		// uint32 elements are packed 8 per slot
+		{"users[" + testHolder.Hex() + "].ids[9]", slotAdd(arraySlot(slotAdd(user, 2)), 1), 4, "uint32"},
+		{"users[" + testHolder.Hex() + "].approved[" + testSpender.Hex() + "]",
+			MappingSlot(testSpender.Bytes(), slotAdd(user, 3).Big()), 0, "bool"},
+		{"owners[5]", slotAdd(owners, 5), 0, "address"},
+		{"small[2]", slotN(5), 4, "uint16"},
+		{"allowances[" + testHolder.Hex() + "][" + strings.ToLower(testSpender.Hex()) + "]",
+			MappingSlot(testSpender.Bytes(), MappingSlot(testHolder.Bytes(), big.NewInt(6)).Big()), 0, "uint256"},
+		// string keys are hashed unpadded
+		{`byName["alice"]`, crypto.Keccak256Hash([]byte("alice"), slotN(4).Bytes()), 0, "uint256"},
+		{`byName['a]b']`, crypto.Keccak256Hash([]byte("a]b"), slotN(4).Bytes()), 0, "uint256"},
+		{"byInt[-1]", MappingSlot(math.U256Bytes(big.NewInt(-1)), big.NewInt(8)), 0, "uint256"},
+	}
+	for _, tt := range tests {
+		loc, err := layout.Locate(tt.path)
+		if err != nil {
+			t.Fatalf("locate %s error %s", tt.path, err)
+		}
+		if loc.Slot != tt.slot || loc.Offset != tt.offset || loc.Type.Label != tt.typ {
+			t.Errorf("locate %s got slot %s offset %d type %s, want slot %s offset %d type %s",
+				tt.path, loc.Slot.Hex(), loc.Offset, loc.Type.Label, tt.slot.Hex(), tt.offset, tt.typ)
+		}
+	}
+	for _, path := range []string{
+		"nope", "a.b", "a[0]", "users[0xabc]", "users[" + testHolder.Hex() + "].nope", "small[3]", "small[x]",
+		"owners[-1]", "owners[1", "byInt[abc]", "a b",
+	} {
+		if _, err = layout.Locate(path); err == nil {
+			t.Errorf("locate %s should fail", path)
+		}
+	}
+}
+
+func TestStorageRead(t *testing.T) {
+	layout, err := LoadStorageLayout(testStorageLayout)
+	if err != nil {
+		t.Fatalf("load storage layout error %s", err)
+	}
+	words := make(map[common.Hash][]byte)
+	set := func(slot common.Hash, offset int, value []byte) {
+		word := words[slot]
+		if word == nil {
+			word = make([]byte, 32)
+			words[slot] = word
+		}
+		copy(word[32-offset-len(value):], value)
+	}
+	// packed slot 0: a = 5, b = 7, c = true, d = -2, sel = 0xa9059cbb
+	set(slotN(0), 0, []byte{5})
+	set(slotN(0), 16, []byte{7})
+	set(slotN(0), 24, []byte{1})
+	set(slotN(0), 25, []byte{0xfe})
+	set(slotN(0), 26, []byte{0xa9, 0x05, 0x9c, 0xbb})
+	// users[holder] = {amount: 1000, owner: holder, ids: [0, 11, 0, 0, 0, 0, 0, 0, 42]}
+	user := MappingSlot(testHolder.Bytes(), big.NewInt(1))
+	set(user, 0, big.NewInt(1000).Bytes())
+	set(slotAdd(user, 1), 0, testHolder.Bytes())
+	set(slotAdd(user, 2), 0, []byte{9})
+	ids := arraySlot(slotAdd(user, 2))
+	set(ids, 4, []byte{11})
+	set(slotAdd(ids, 1), 0, []byte{42})
+	// owners = [0x0, spender]
+	set(slotN(2), 0, []byte{2})
+	set(slotAdd(arraySlot(slotN(2)), 1), 0, testSpender.Bytes())
+	// short string is stored in place with length * 2 in the lowest byte
+	words[slotN(3)] = append([]byte("short name"), make([]byte, 22)...)
+	words[slotN(3)][31] = byte(len("short name") * 2)
+	// long bytes: length * 2 + 1 in the slot, data from keccak256(slot)
+	long := bytes.Repeat([]byte{0xab, 0xcd}, 20)
+	set(slotN(7), 0, []byte{byte(len(long)*2 + 1)})
+	words[arraySlot(slotN(7))] = long[:32]
+	words[slotAdd(arraySlot(slotN(7)), 1)] = append(common.CopyBytes(long[32:]), make([]byte, 24)...)
+	set(slotN(5), 0, []byte{1})
+	set(slotN(5), 2, []byte{2})
+	set(slotN(5), 4, []byte{3})
+
+	reads := 0
+	storage := func(slot common.Hash) ([]byte, error) {
+		reads++
+		// nodes return the value without leading zeros
+		return common.TrimLeftZeroes(words[slot]), nil
+	}
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"a", big.NewInt(5)},
+		{"b", uint64(7)},
+		{"c", true},
+		{"d", int8(-2)},
+		{"sel", [4]byte{0xa9, 0x05, 0x9c, 0xbb}},
+		{"users[" + testHolder.Hex() + "].ids[1]", uint32(11)},
+		{"users[" + testHolder.Hex() + "].ids[8]", uint32(42)},
+		// the mapping member is left out of the struct
+		{"users[" + testHolder.Hex() + "]", map[string]interface{}{
+			"amount": big.NewInt(1000),
+			"owner":  testHolder,
+			"ids":    []interface{}{uint32(0), uint32(11), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(42)},
+		}},
+		{"users[" + testHolder.Hex() + "].approved[" + testSpender.Hex() + "]", false},
+		{"owners", []interface{}{common.Address{}, testSpender}},
+		{"name", "short name"},
+		{"data", long},
+		{"small", []interface{}{uint16(1), uint16(2), uint16(3)}},
+		{`byName["nobody"]`, big.NewInt(0)},
+	}
+	for _, tt := range tests {
+		got, err := layout.read(tt.path, storage)
+		if err != nil {
+			t.Fatalf("read %s error %s", tt.path, err)
+		}
+		// compare printed values, big.Int of zero differs in its internal slice
+		if fmt.Sprintf("%T %v", got, got) != fmt.Sprintf("%T %v", tt.want, tt.want) {
+			t.Errorf("read %s got %v (%T), want %v (%T)", tt.path, got, got, tt.want, tt.want)
+		}
+	}
+	if reads == 0 {
+		t.Fatalf("storage not read")
+	}
+	for _, path := range []string{"users", "owners[2]", "users[" + testHolder.Hex() + "].ids[9]"} {
+		if _, err = layout.read(path, storage); err == nil {
+			t.Errorf("read %s should fail", path)
+		}
+	}
+}
+
+func TestStorageReadInvalidBytes(t *testing.T) {
+	layout, err := LoadStorageLayout(testStorageLayout)
+	if err != nil {
+		t.Fatalf("load storage layout error %s", err)
+	}
+	// an even lowest byte of 66 or more is no short string, e.g. the slot of another variable
+	for _, last := range []byte{66, 0xfe} {
+		word := make([]byte, 32)
+		word[31] = last
+		storage := func(slot common.Hash) ([]byte, error) { return word, nil }
+		for _, path := range []string{"name", "data"} {
+			if _, err = layout.read(path, storage); err == nil {
+				t.Errorf("read %s of lowest byte %d should fail", path, last)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	callTracer = "callTracer"
+)
+
+// CallTracerConfig is the config of the geth callTracer
+type CallTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+	WithLog     bool `json:"withLog,omitempty"`
+}
+
+// CallFrame is a call of the callTracer call tree, Method/Outputs/Events/RevertReason are decoded by the ABI registry
+type CallFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           common.Address  `json:"to"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Logs         []*CallFrameLog `json:"logs,omitempty"`
+	Calls        []*CallFrame    `json:"calls,omitempty"`
+
+	Method  *CallMethod   `json:"-"`
+	Outputs []interface{} `json:"-"`
+}
+
+// CallFrameLog is a log emitted by a call frame (callTracer withLog)
+type CallFrameLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+
+	Event *CallEvent `json:"-"`
+}
+
+// TraceTransaction trace transaction by debug_traceTransaction with callTracer and decode each frame by the ABI registry
+func (m *EthereumClient) TraceTransaction(ctx context.Context, hash string, config *CallTracerConfig) (frame *CallFrame, err error) {
+	if config == nil {
+		config = &CallTracerConfig{}
+	}
+	frame = &CallFrame{}
+	err = m.ethcli.Client().CallContext(ctx, frame, "debug_traceTransaction", Hex2Hash(hash), map[string]interface{}{
+		"tracer":       callTracer,
+		"tracerConfig": config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("trace tx [%s] error [%s]", hash, err)
+	}
+	m.DecodeCallFrame(frame)
+	return frame, nil
+}
+
+// DecodeCallFrame decode input, output, revert reason and logs of frame and its sub calls by the ABI registry
+func (m *EthereumClient) DecodeCallFrame(frame *CallFrame) {
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		m.decodeCallFrame(f)
+		return true
+	})
+}
+
+func (m *EthereumClient) decodeCallFrame(f *CallFrame) {
+	to := f.To.String()
+	if len(f.Input) >= 4 && !f.IsCreate() {
+		if method, contractABI, err := m.Registry.MethodById(to, f.Input); err == nil {
+			f.Method = &CallMethod{
+				Method: method,
+				ABI:    contractABI,
+				Data:   f.Input[4:],
+			}
+			if f.Error == "" && len(f.Output) != 0 {
+				f.Outputs, _ = method.Outputs.UnpackValues(f.Output)
+			}
+		}
+	}
+	if f.Error != "" && f.RevertReason == "" && len(f.Output) >= 4 {
+		f.RevertReason, _ = m.Registry.UnpackRevert(to, f.Output)
+	}
+	for _, lo := range f.Logs {
+		if len(lo.Topics) == 0 {
+			continue
+		}
+		if event, contractABI, err := m.Registry.EventByLog(lo.Address.String(), lo.Topics); err == nil {
+			lo.Event = &CallEvent{
+				Event: event,
+				ABI:   contractABI,
+				Log:   types.Log{Address: lo.Address, Topics: lo.Topics, Data: lo.Data},
+			}
+		}
+	}
+}
+
+// IsCreate reports whether the frame is a contract creation (CREATE or CREATE2)
+func (f *CallFrame) IsCreate() bool {
+	return f.Type == "CREATE" || f.Type == "CREATE2"
+}
+
+// Walk visit the frame and its sub calls depth first, the sub calls of a frame are skipped if fn returns false
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int) bool) {
+	f.walk(fn, 0)
+}
+
+func (f *CallFrame) walk(fn func(frame *CallFrame, depth int) bool, depth int) {
+	if !fn(f, depth) {
+		return
+	}
+	for _, c := range f.Calls {
+		c.walk(fn, depth+1)
+	}
+}
+
+// FailedFrame returns the frame where the revert happened by following the failed sub calls whose revert data
+// bubbled up to the caller, nil if the frame did not fail
+func (f *CallFrame) FailedFrame() *CallFrame {
+	if f.Error == "" {
+		return nil
+	}
+	for i := len(f.Calls) - 1; i >= 0; i-- {
+		c := f.Calls[i]
+		if c.Error != "" && bytes.Equal(c.Output, f.Output) {
+			return c.FailedFrame()
+		}
+	}
+	return f
+}
@@ -0,0 +1,144 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxOption is the options of building a transaction
+type TxOption struct {
+	AccessList bool // attach the access list generated by eth_createAccessList when it lowers the estimated gas
+}
+
+// NewTransaction build and sign a transaction to contract (empty for contract creation) with data by transact options,
+// the nonce, fees and gas limit not set in opts are filled from the node. A dynamic fee transaction is built unless
+// opts.GasPrice is set or the chain has no base fee
+func (m *EthereumClient) NewTransaction(ctx context.Context, opts *bind.TransactOpts, strTo string, data []byte, txOpt *TxOption) (tx *types.Transaction, err error) {
+	if txOpt == nil {
+		txOpt = &TxOption{}
+	}
+	var to *common.Address
+	if strTo != "" {
+		addr := Hex2Address(strTo)
+		to = &addr
+	}
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	var chainId int64
+	chainId, err = m.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	} else if nonce, err = m.ethcli.PendingNonceAt(ctx, opts.From); err != nil {
+		return nil, fmt.Errorf("get pending nonce of [%s] error [%s]", opts.From, err)
+	}
+
+	msg := ethereum.CallMsg{From: opts.From, To: to, Value: value, Data: data}
+	gasPrice, gasFeeCap, gasTipCap := opts.GasPrice, opts.GasFeeCap, opts.GasTipCap
+	if gasPrice == nil {
+		var head *types.Header
+		head, err = m.ethcli.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if head.BaseFee == nil {
+			if gasPrice, err = m.ethcli.SuggestGasPrice(ctx); err != nil {
+				return nil, err
+			}
+		} else {
+			if gasTipCap == nil {
+				if gasTipCap, err = m.ethcli.SuggestGasTipCap(ctx); err != nil {
+					return nil, err
+				}
+			}
+			if gasFeeCap == nil {
+				gasFeeCap = new(big.Int).Add(gasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+			}
+			if gasFeeCap.Cmp(gasTipCap) < 0 {
+				return nil, fmt.Errorf("max fee per gas %s < max priority fee per gas %s", gasFeeCap, gasTipCap)
+			}
+		}
+	}
+
+	var estimated uint64
+	if opts.GasLimit == 0 || txOpt.AccessList {
+		if estimated, err = m.ethcli.EstimateGas(ctx, msg); err != nil {
+			return nil, fmt.Errorf("estimate gas error [%s]", err)
+		}
+	}
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		gasLimit = estimated
+	}
+	var accessList types.AccessList
+	if txOpt.AccessList {
+		var withList uint64
+		if accessList, withList, err = m.accessListIfCheaper(ctx, msg, estimated); err != nil {
+			return nil, err
+		}
+		if accessList != nil && opts.GasLimit == 0 {
+			gasLimit = withList
+		}
+	}
+
+	var txData types.TxData
+	switch {
+	case gasPrice != nil && accessList == nil:
+		txData = &types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: gasLimit, To: to, Value: value, Data: data}
+	case gasPrice != nil:
+		txData = &types.AccessListTx{ChainID: big.NewInt(chainId), Nonce: nonce, GasPrice: gasPrice, Gas: gasLimit, To: to, Value: value, Data: data, AccessList: accessList}
+	default:
+		txData = &types.DynamicFeeTx{ChainID: big.NewInt(chainId), Nonce: nonce, GasTipCap: gasTipCap, GasFeeCap: gasFeeCap, Gas: gasLimit, To: to, Value: value, Data: data, AccessList: accessList}
+	}
+	if opts.Signer == nil {
+		return nil, fmt.Errorf("no signer to authorize the transaction with")
+	}
+	return opts.Signer(opts.From, types.NewTx(txData))
+}
+
+// Transact build, sign and send a transaction to contract (empty for contract creation) with data by transact options
+func (m *EthereumClient) Transact(ctx context.Context, opts *bind.TransactOpts, strTo string, data []byte, txOpt *TxOption) (tx *types.Transaction, err error) {
+	tx, err = m.NewTransaction(ctx, opts, strTo, data, txOpt)
+	if err != nil {
+		return nil, err
+	}
+	if opts.NoSend {
+		return tx, nil
+	}
+	if err = m.ethcli.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// accessListIfCheaper returns the access list generated by eth_createAccessList and the estimated gas with it if it is
+// lower than estimated, the estimated gas without the access list
+func (m *EthereumClient) accessListIfCheaper(ctx context.Context, msg ethereum.CallMsg, estimated uint64) (types.AccessList, uint64, error) {
+	accessList, _, err := m.CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if accessList == nil || len(*accessList) == 0 {
+		return nil, 0, nil
+	}
+	msg.AccessList = *accessList
+	withList, err := m.estimateGas(ctx, msg)
+	if err != nil {
+		return nil, 0, fmt.Errorf("estimate gas with access list error [%s]", err)
+	}
+	if withList >= estimated {
+		return nil, 0, nil
+	}
+	return *accessList, withList, nil
+}
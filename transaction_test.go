@@ -0,0 +1,86 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeAccessListNode serve eth_estimateGas of gas without access list and withList with it, and eth_createAccessList
+// of a list used gasUsed
+type fakeAccessListNode struct {
+	gas       uint64
+	withList  uint64
+	gasUsed   uint64
+	estimates int // count of eth_estimateGas calls
+}
+
+type fakeAccessListArgs struct {
+	To         common.Address   `json:"to"`
+	AccessList types.AccessList `json:"accessList"`
+}
+
+type fakeAccessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"`
+}
+
+func (f *fakeAccessListNode) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (f *fakeAccessListNode) EstimateGas(args fakeAccessListArgs) hexutil.Uint64 {
+	f.estimates++
+	if len(args.AccessList) != 0 {
+		return hexutil.Uint64(f.withList)
+	}
+	return hexutil.Uint64(f.gas)
+}
+
+func (f *fakeAccessListNode) CreateAccessList(args fakeCallArgs, block string) *fakeAccessListResult {
+	return &fakeAccessListResult{
+		AccessList: types.AccessList{{Address: args.To, StorageKeys: []common.Hash{common.BigToHash(big.NewInt(1))}}},
+		GasUsed:    hexutil.Uint64(f.gasUsed),
+	}
+}
+
+func TestNewTransactionAccessList(t *testing.T) {
+	tests := []struct {
+		name       string
+		node       *fakeAccessListNode
+		gasLimit   uint64
+		accessList bool
+		gas        uint64
+	}{
+		{"cheaper", &fakeAccessListNode{gas: 50000, withList: 49000, gasUsed: 47000}, 0, true, 49000},
+		// gas used with the list is lower than the estimate without it, but the estimate with it is not
+		{"not cheaper", &fakeAccessListNode{gas: 50000, withList: 50100, gasUsed: 47000}, 0, false, 50000},
+		{"fixed gas limit", &fakeAccessListNode{gas: 50000, withList: 49000, gasUsed: 47000}, 80000, true, 80000},
+	}
+	for _, tt := range tests {
+		m := newTestClient(t, "eth", tt.node)
+		opts := &bind.TransactOpts{
+			From:     Hex2Address(testAddress),
+			Nonce:    big.NewInt(1),
+			GasPrice: big.NewInt(1000000000),
+			GasLimit: tt.gasLimit,
+			Signer:   func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) { return tx, nil },
+		}
+		tx, err := m.NewTransaction(context.Background(), opts, "0x00000000000000000000000000000000000000c0", []byte{1}, &TxOption{AccessList: true})
+		if err != nil {
+			t.Fatalf("%s: new transaction error %s", tt.name, err)
+		}
+		// one estimate without and one with the access list
+		if tt.node.estimates != 2 {
+			t.Errorf("%s: %d eth_estimateGas calls, want 2", tt.name, tt.node.estimates)
+		}
+		if (len(tx.AccessList()) != 0) != tt.accessList || tx.Gas() != tt.gas {
+			t.Errorf("%s: transaction of gas %d access list %v", tt.name, tx.Gas(), tx.AccessList())
+		}
+	}
+}
@@ -4,16 +4,64 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 	"math/big"
 	"reflect"
 	"strings"
 )
 
+// rpcMethodNotFound is the JSON-RPC error code of a method not found or disabled
+const rpcMethodNotFound = -32601
+
+// rpcExecutionReverted is the JSON-RPC error code of an eth_call or eth_estimateGas reverted with data
+const rpcExecutionReverted = 3
+
+// isNotSupported reports whether the rpc error means the node does not support the method or its parameters,
+// execution errors such as reverts are not
+func isNotSupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		// revert data
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcMethodNotFound {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "does not exist/is not available", "not supported", "unsupported"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReverted reports whether the rpc error is an execution revert of the call, with or without revert data
+func isReverted(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcExecutionReverted {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "execution reverted")
+}
+
 // NewTransactOpts new transact options by private key string or *ecdsa.PrivateKey object and chain id
 func NewTransactOpts(privateKey interface{}, chainId int64) (txOpts *bind.TransactOpts, err error) {
 	var pk *ecdsa.PrivateKey
@@ -104,26 +152,13 @@ func Hex2Address(addr string) common.Address {
 // newKeyedTransactorWithValue is a utility method to easily create a transaction signer
 // from a single private key and value.
 func newKeyedTransactorWithValue(key *ecdsa.PrivateKey, chainID *big.Int, value interface{}) (*bind.TransactOpts, error) {
-	var ok bool
 	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
 	if chainID == nil {
 		return nil, bind.ErrNoChainID
 	}
-	var bigValue = big.NewInt(0)
-	switch value.(type) {
-	case *big.Int:
-		bigValue = value.(*big.Int)
-	case string:
-		bigValue, ok = bigValue.SetString(value.(string), 10)
-		if !ok {
-			return nil, fmt.Errorf("value '%v' invalid", value.(string))
-		}
-	default:
-		strValue := fmt.Sprintf("%v", value)
-		bigValue, ok = bigValue.SetString(strValue, 10)
-		if !ok {
-			return nil, fmt.Errorf("value '%v' invalid", value)
-		}
+	bigValue, err := parseBigValue(value)
+	if err != nil {
+		return nil, err
 	}
 	signer := types.LatestSignerForChainID(chainID)
 	return &bind.TransactOpts{
@@ -142,3 +177,25 @@ func newKeyedTransactorWithValue(key *ecdsa.PrivateKey, chainID *big.Int, value
 		Value:   bigValue,
 	}, nil
 }
+
+// parseBigValue parse a *big.Int, decimal string or any other printable number into *big.Int
+func parseBigValue(value interface{}) (*big.Int, error) {
+	var ok bool
+	var bigValue = big.NewInt(0)
+	switch value.(type) {
+	case *big.Int:
+		bigValue = value.(*big.Int)
+	case string:
+		bigValue, ok = bigValue.SetString(value.(string), 10)
+		if !ok {
+			return nil, fmt.Errorf("value '%v' invalid", value.(string))
+		}
+	default:
+		strValue := fmt.Sprintf("%v", value)
+		bigValue, ok = bigValue.SetString(strValue, 10)
+		if !ok {
+			return nil, fmt.Errorf("value '%v' invalid", value)
+		}
+	}
+	return bigValue, nil
+}
@@ -0,0 +1,233 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// InclusionProof is the Merkle-Patricia proof of the item at Index of a block transactions or receipts trie
+type InclusionProof struct {
+	Root  common.Hash
+	Index uint64
+	Proof [][]byte
+}
+
+// proofList collects the proof nodes written by trie.Prove
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	return fmt.Errorf("proof list does not support delete")
+}
+
+// VerifyBlockTransactions check the transactions, uncles and withdrawals of block against the roots of its header
+func VerifyBlockTransactions(block *types.Block) error {
+	header := block.Header()
+	if root := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); root != header.TxHash {
+		return fmt.Errorf("block [%d] transactions root %s mismatch with header %s", block.NumberU64(), root.Hex(), header.TxHash.Hex())
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+		return fmt.Errorf("block [%d] uncles hash %s mismatch with header %s", block.NumberU64(), hash.Hex(), header.UncleHash.Hex())
+	}
+	if header.WithdrawalsHash != nil {
+		if root := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); root != *header.WithdrawalsHash {
+			return fmt.Errorf("block [%d] withdrawals root %s mismatch with header %s", block.NumberU64(), root.Hex(), header.WithdrawalsHash.Hex())
+		}
+	}
+	return nil
+}
+
+// VerifyBlockReceipts check the receipts (in transaction order) against the receipts root of header
+func VerifyBlockReceipts(header *types.Header, receipts []*types.Receipt) error {
+	if root := types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil)); root != header.ReceiptHash {
+		return fmt.Errorf("block [%d] receipts root %s mismatch with header %s", header.Number, root.Hex(), header.ReceiptHash.Hex())
+	}
+	return nil
+}
+
+// VerifiedBlockByNumber returns the block and receipts of block number verified against the header roots
+func (m *EthereumClient) VerifiedBlockByNumber(ctx context.Context, number uint64) (block *types.Block, receipts []*types.Receipt, err error) {
+	block, err = m.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get block by number [%d] error [%s]", number, err)
+	}
+	if block.NumberU64() != number {
+		return nil, nil, fmt.Errorf("block %s of number [%d] returned for number [%d]", block.Hash().Hex(), block.NumberU64(), number)
+	}
+	if err = VerifyBlockTransactions(block); err != nil {
+		return nil, nil, err
+	}
+	receipts, err = m.blockReceipts(ctx, block)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = VerifyBlockReceipts(block.Header(), receipts); err != nil {
+		return nil, nil, err
+	}
+	return block, receipts, nil
+}
+
+// TransactionProof returns the verified block header and the inclusion proof of transaction in the block transactions trie
+func (m *EthereumClient) TransactionProof(ctx context.Context, hash string) (header *types.Header, proof *InclusionProof, err error) {
+	var block *types.Block
+	var index uint64
+	block, index, err = m.blockOfTransaction(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = VerifyBlockTransactions(block); err != nil {
+		return nil, nil, err
+	}
+	proof, err = NewInclusionProof(block.Transactions(), index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block.Header(), proof, nil
+}
+
+// ReceiptProof returns the verified block header and the inclusion proof of transaction receipt in the block receipts trie
+func (m *EthereumClient) ReceiptProof(ctx context.Context, hash string) (header *types.Header, proof *InclusionProof, err error) {
+	var block *types.Block
+	var index uint64
+	block, index, err = m.blockOfTransaction(ctx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var receipts []*types.Receipt
+	receipts, err = m.blockReceipts(ctx, block)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = VerifyBlockReceipts(block.Header(), receipts); err != nil {
+		return nil, nil, err
+	}
+	proof, err = NewInclusionProof(types.Receipts(receipts), index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block.Header(), proof, nil
+}
+
+// NewInclusionProof build the trie of list (types.Transactions or types.Receipts) and returns the proof of the item at index
+func NewInclusionProof(list types.DerivableList, index uint64) (*InclusionProof, error) {
+	if index >= uint64(list.Len()) {
+		return nil, fmt.Errorf("index %d out of range %d", index, list.Len())
+	}
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	var buf bytes.Buffer
+	for i := 0; i < list.Len(); i++ {
+		buf.Reset()
+		list.EncodeIndex(i, &buf)
+		if err := tr.Update(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(buf.Bytes())); err != nil {
+			return nil, err
+		}
+	}
+	var nodes proofList
+	if err := tr.Prove(rlp.AppendUint64(nil, index), 0, &nodes); err != nil {
+		return nil, err
+	}
+	return &InclusionProof{
+		Root:  tr.Hash(),
+		Index: index,
+		Proof: nodes,
+	}, nil
+}
+
+// Value returns the encoded item proved by the proof against its root
+func (p *InclusionProof) Value() ([]byte, error) {
+	value, err := verifyProof(p.Root, rlp.AppendUint64(nil, p.Index), p.Proof)
+	if err != nil {
+		return nil, fmt.Errorf("verify inclusion proof of index %d error [%s]", p.Index, err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("index %d not included in root %s", p.Index, p.Root.Hex())
+	}
+	return value, nil
+}
+
+// VerifyTransactionProof check the proof offline against the transactions root of header and returns the proved transaction
+func VerifyTransactionProof(header *types.Header, proof *InclusionProof) (*types.Transaction, error) {
+	if proof.Root != header.TxHash {
+		return nil, fmt.Errorf("proof root %s mismatch with transactions root %s", proof.Root.Hex(), header.TxHash.Hex())
+	}
+	value, err := proof.Value()
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(value); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// VerifyReceiptProof check the proof offline against the receipts root of header and returns the proved receipt
+// (consensus fields only)
+func VerifyReceiptProof(header *types.Header, proof *InclusionProof) (*types.Receipt, error) {
+	if proof.Root != header.ReceiptHash {
+		return nil, fmt.Errorf("proof root %s mismatch with receipts root %s", proof.Root.Hex(), header.ReceiptHash.Hex())
+	}
+	value, err := proof.Value()
+	if err != nil {
+		return nil, err
+	}
+	receipt := new(types.Receipt)
+	if err = receipt.UnmarshalBinary(value); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// blockOfTransaction returns the block including transaction and its index, the block must be of the receipt block
+// hash and the transaction at the index of the receipt must be the requested one
+func (m *EthereumClient) blockOfTransaction(ctx context.Context, hash string) (block *types.Block, index uint64, err error) {
+	var receipt *types.Receipt
+	receipt, err = m.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get receipt by hash [%s] error [%s]", hash, err)
+	}
+	block, err = m.ethcli.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get block by hash [%s] error [%s]", receipt.BlockHash.Hex(), err)
+	}
+	// the roots are only as good as the header, which must be the block of receipt
+	if block.Hash() != receipt.BlockHash {
+		return nil, 0, fmt.Errorf("block %s returned for block hash [%s] of tx [%s]", block.Hash().Hex(), receipt.BlockHash.Hex(), hash)
+	}
+	txs := block.Transactions()
+	index = uint64(receipt.TransactionIndex)
+	if index >= uint64(len(txs)) {
+		return nil, 0, fmt.Errorf("tx [%s] index %d out of range of %d txs in block %s", hash, index, len(txs), block.Hash().Hex())
+	}
+	if txs[index].Hash() != Hex2Hash(hash) {
+		return nil, 0, fmt.Errorf("tx at index %d of block %s is [%s] not [%s]", index, block.Hash().Hex(), txs[index].Hash().Hex(), hash)
+	}
+	return block, index, nil
+}
+
+// blockReceipts returns the receipts of block transactions in order
+func (m *EthereumClient) blockReceipts(ctx context.Context, block *types.Block) (receipts []*types.Receipt, err error) {
+	for _, tx := range block.Transactions() {
+		var receipt *types.Receipt
+		receipt, err = m.ethcli.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("get receipt by hash [%s] error [%s]", tx.Hash().Hex(), err)
+		}
+		if receipt.BlockHash != block.Hash() {
+			return nil, fmt.Errorf("receipt of tx [%s] block hash %s mismatch with block %s", tx.Hash().Hex(), receipt.BlockHash.Hex(), block.Hash().Hex())
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
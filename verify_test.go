@@ -0,0 +1,126 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func testTransactions(n int) (txs types.Transactions) {
+	for i := 0; i < n; i++ {
+		txs = append(txs, types.NewTx(&types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(1), Gas: 21000, Value: big.NewInt(int64(i)), Data: make([]byte, 64)}))
+	}
+	return txs
+}
+
+func TestTransactionInclusionProof(t *testing.T) {
+	txs := testTransactions(20)
+	header := &types.Header{TxHash: types.DeriveSha(txs, trie.NewStackTrie(nil))}
+	proof, err := NewInclusionProof(txs, 13)
+	if err != nil {
+		t.Fatalf("new inclusion proof error %s", err)
+	}
+	tx, err := VerifyTransactionProof(header, proof)
+	if err != nil {
+		t.Fatalf("verify transaction proof error %s", err)
+	}
+	if tx.Hash() != txs[13].Hash() {
+		t.Fatalf("proved tx %s, want %s", tx.Hash().Hex(), txs[13].Hash().Hex())
+	}
+	proof.Index = 14
+	if _, err = VerifyTransactionProof(header, proof); err == nil {
+		t.Fatalf("verify transaction proof of other index should fail")
+	}
+}
+
+// fakeBlockNode serve a block of txs for any hash or number and receipts reporting the tx at index, in the block of
+// blockHash if set
+type fakeBlockNode struct {
+	header    *types.Header
+	txs       types.Transactions
+	index     uint
+	blockHash common.Hash
+}
+
+func (f *fakeBlockNode) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	blockHash := f.blockHash
+	if blockHash == (common.Hash{}) {
+		blockHash = f.header.Hash()
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}, TxHash: hash,
+		BlockHash: blockHash, TransactionIndex: f.index}
+}
+
+func (f *fakeBlockNode) GetBlockByNumber(number string, full bool) (map[string]interface{}, error) {
+	return f.GetBlockByHash(common.Hash{}, full)
+}
+
+func (f *fakeBlockNode) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	data, err := json.Marshal(f.header)
+	if err != nil {
+		return nil, err
+	}
+	block := map[string]interface{}{}
+	if err = json.Unmarshal(data, &block); err != nil {
+		return nil, err
+	}
+	block["transactions"] = f.txs
+	block["uncles"] = []common.Hash{}
+	return block, nil
+}
+
+func TestTransactionProofIndex(t *testing.T) {
+	txs := testTransactions(4)
+	header := &types.Header{
+		Number:      big.NewInt(1),
+		Difficulty:  new(big.Int),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.DeriveSha(txs, trie.NewStackTrie(nil)),
+		ReceiptHash: types.EmptyReceiptsHash,
+	}
+	hash := txs[2].Hash().Hex()
+	m := newTestClient(t, "eth", &fakeBlockNode{header: header, txs: txs, index: 2})
+	proved, proof, err := m.TransactionProof(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("transaction proof error %s", err)
+	}
+	if tx, err := VerifyTransactionProof(proved, proof); err != nil || tx.Hash() != txs[2].Hash() {
+		t.Fatalf("verify transaction proof error %v", err)
+	}
+	// a receipt of wrong index would prove another tx of the block
+	for index, want := range map[uint]string{3: "not [" + hash + "]", 4: "out of range"} {
+		m = newTestClient(t, "eth", &fakeBlockNode{header: header, txs: txs, index: index})
+		if _, _, err = m.TransactionProof(context.Background(), hash); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("transaction proof of receipt index %d should fail by %q, got error %v", index, want, err)
+		}
+	}
+}
+
+func TestProofBlockMismatch(t *testing.T) {
+	txs := testTransactions(2)
+	header := &types.Header{
+		Number:      big.NewInt(1),
+		Difficulty:  new(big.Int),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.DeriveSha(txs, trie.NewStackTrie(nil)),
+		ReceiptHash: types.EmptyReceiptsHash,
+	}
+	// the node returns a self-consistent block other than the one of receipt
+	m := newTestClient(t, "eth", &fakeBlockNode{header: header, txs: txs, index: 1, blockHash: common.HexToHash("0x01")})
+	if _, _, err := m.TransactionProof(context.Background(), txs[1].Hash().Hex()); err == nil || !strings.Contains(err.Error(), "returned for block hash") {
+		t.Errorf("transaction proof of other block should fail, got error %v", err)
+	}
+	if _, _, err := m.ReceiptProof(context.Background(), txs[1].Hash().Hex()); err == nil || !strings.Contains(err.Error(), "returned for block hash") {
+		t.Errorf("receipt proof of other block should fail, got error %v", err)
+	}
+	m = newTestClient(t, "eth", &fakeBlockNode{header: header, txs: txs})
+	if _, _, err := m.VerifiedBlockByNumber(context.Background(), 2); err == nil || !strings.Contains(err.Error(), "returned for number [2]") {
+		t.Errorf("verified block of other number should fail, got error %v", err)
+	}
+}